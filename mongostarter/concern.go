@@ -0,0 +1,94 @@
+package mongostarter
+
+import (
+	"context"
+
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+	"go.mongodb.org/mongo-driver/v2/mongo/readconcern"
+	"go.mongodb.org/mongo-driver/v2/mongo/readpref"
+	"go.mongodb.org/mongo-driver/v2/mongo/writeconcern"
+)
+
+// ReadWriteOptions 集合级读写选项 nil 字段沿用下一层级的设置
+// 优先级为 context、Mapper、模型、客户端默认值
+type ReadWriteOptions struct {
+	// 读偏好 标签与最大延迟通过 readpref.WithTagSets、readpref.WithMaxStaleness 指定
+	ReadPreference *readpref.ReadPref
+	// 读关注
+	ReadConcern *readconcern.ReadConcern
+	// 写关注
+	WriteConcern *writeconcern.WriteConcern
+}
+
+// ReadWriteModel 模型实现该接口声明集合默认读写选项
+type ReadWriteModel interface {
+	Model
+	ReadWriteOptions() ReadWriteOptions
+}
+
+type readWriteContextKey struct{}
+
+// WithReadWriteOptions 返回携带读写选项的 context 用于覆盖单次 Mapper 调用的读写选项
+// 多次调用时按字段合并 后设置的字段优先
+func WithReadWriteOptions(ctx context.Context, opts ReadWriteOptions) context.Context {
+	if parent, ok := ctx.Value(readWriteContextKey{}).(ReadWriteOptions); ok {
+		opts = opts.merge(parent)
+	}
+	return context.WithValue(ctx, readWriteContextKey{}, opts)
+}
+
+// WithReadWrite 返回绑定读写选项的 Mapper 副本 覆盖模型声明的读写选项
+func (b BaseMapper[T]) WithReadWrite(opts ReadWriteOptions) BaseMapper[T] {
+	if b.readWrite != nil {
+		opts = opts.merge(*b.readWrite)
+	}
+	b.readWrite = &opts
+	return b
+}
+
+// readWriteOptions 按优先级合并模型、Mapper 与 context 中的读写选项
+func (b BaseMapper[T]) readWriteOptions(ctx context.Context) ReadWriteOptions {
+	var opts ReadWriteOptions
+	if ctx != nil {
+		opts, _ = ctx.Value(readWriteContextKey{}).(ReadWriteOptions)
+	}
+	if b.readWrite != nil {
+		opts = opts.merge(*b.readWrite)
+	}
+	if model, ok := any(b.model).(ReadWriteModel); ok {
+		opts = opts.merge(model.ReadWriteOptions())
+	}
+	return opts
+}
+
+// merge 以 o 为准 使用 fallback 补全 nil 字段
+func (o ReadWriteOptions) merge(fallback ReadWriteOptions) ReadWriteOptions {
+	if o.ReadPreference == nil {
+		o.ReadPreference = fallback.ReadPreference
+	}
+	if o.ReadConcern == nil {
+		o.ReadConcern = fallback.ReadConcern
+	}
+	if o.WriteConcern == nil {
+		o.WriteConcern = fallback.WriteConcern
+	}
+	return o
+}
+
+// collectionOptions 转换为集合选项 未设置任何字段时返回空列表
+func (o ReadWriteOptions) collectionOptions() []options.Lister[options.CollectionOptions] {
+	if o.ReadPreference == nil && o.ReadConcern == nil && o.WriteConcern == nil {
+		return nil
+	}
+	opts := options.Collection()
+	if o.ReadPreference != nil {
+		opts.SetReadPreference(o.ReadPreference)
+	}
+	if o.ReadConcern != nil {
+		opts.SetReadConcern(o.ReadConcern)
+	}
+	if o.WriteConcern != nil {
+		opts.SetWriteConcern(o.WriteConcern)
+	}
+	return []options.Lister[options.CollectionOptions]{opts}
+}
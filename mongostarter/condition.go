@@ -0,0 +1,201 @@
+package mongostarter
+
+import (
+	"reflect"
+	"slices"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// 全局实体条件模式 受 mongoLock 保护
+var conditionMode ConditionMode
+
+// ConditionMode 实体条件转换为过滤条件的方式
+type ConditionMode int
+
+const (
+	// ConditionStruct 直接编码实体作为过滤条件 未声明 omitempty 的零值字段同样参与匹配
+	ConditionStruct ConditionMode = iota
+	// ConditionNonZero 只使用非零值字段与已设置的指针字段 嵌套结构体展开为点号路径
+	ConditionNonZero
+)
+
+var (
+	bsonMarshalerType = reflect.TypeOf((*bson.Marshaler)(nil)).Elem()
+	zeroCheckerType   = reflect.TypeOf((*interface{ IsZero() bool })(nil)).Elem()
+)
+
+// WithConditionMode 返回使用指定实体条件模式的 Mapper 副本 覆盖 MongoConfig.ConditionMode
+func (b BaseMapper[T]) WithConditionMode(mode ConditionMode) BaseMapper[T] {
+	b.conditionMode = &mode
+	return b
+}
+
+// MatchZero 返回 Mapper 副本 ConditionNonZero 模式下指定路径的字段即使为零值也参与匹配
+// 路径使用 bson 字段名 嵌套字段使用点号路径 如 status、address.city
+func (b BaseMapper[T]) MatchZero(paths ...string) BaseMapper[T] {
+	b.matchZero = append(slices.Clip(b.matchZero), paths...)
+	return b
+}
+
+// condition 按实体条件模式转换条件
+func (b BaseMapper[T]) condition(condition *T) any {
+	mode := b.conditionMode
+	if mode == nil {
+		mongoLock.RLock()
+		global := conditionMode
+		mongoLock.RUnlock()
+		mode = &global
+	}
+	if *mode != ConditionNonZero {
+		return condition
+	}
+	return nonZeroFilter(condition, jsonStructTags(), b.matchZero)
+}
+
+// nonZeroFilter 由非零值字段生成过滤条件 matchZero 中的路径始终参与匹配
+func nonZeroFilter(condition any, jsonTags bool, matchZero []string) bson.D {
+	filter, _ := flatten(condition, jsonTags, matchZero, false)
+	return filter
+}
+
+// flatten 将实体的非零值字段展开为点号路径 unsetNull 为 true 时值为 null 的 Optional 字段放入 unset
+func flatten(entity any, jsonTags bool, matchZero []string, unsetNull bool) (set, unset bson.D) {
+	set = bson.D{}
+	value := reflect.ValueOf(entity)
+	for value.Kind() == reflect.Ptr {
+		if value.IsNil() {
+			return set, nil
+		}
+		value = value.Elem()
+	}
+	if value.Kind() != reflect.Struct {
+		return set, nil
+	}
+	builder := &filterBuilder{jsonTags: jsonTags, filter: set, unsetNull: unsetNull, matchZero: make(map[string]bool, len(matchZero))}
+	for _, path := range matchZero {
+		builder.matchZero[path] = true
+	}
+	builder.fields(value, "")
+	return builder.filter, builder.unset
+}
+
+type filterBuilder struct {
+	jsonTags  bool
+	matchZero map[string]bool
+	filter    bson.D
+	unsetNull bool
+	unset     bson.D
+}
+
+func (b *filterBuilder) fields(value reflect.Value, prefix string) {
+	for i := 0; i < value.NumField(); i++ {
+		info, ok := parseStructField(value.Type().Field(i), b.jsonTags)
+		if !ok {
+			continue
+		}
+		field := value.Field(i)
+		if info.inline {
+			for field.Kind() == reflect.Ptr && !field.IsNil() {
+				field = field.Elem()
+			}
+			if field.Kind() == reflect.Struct {
+				b.fields(field, prefix)
+			}
+			continue
+		}
+		b.value(field, joinPath(prefix, info.name))
+	}
+}
+
+// value 处理单个字段 已设置的指针视为显式条件 即使指向零值也参与匹配
+func (b *filterBuilder) value(field reflect.Value, path string) {
+	if !field.CanInterface() {
+		return
+	}
+	explicit := b.matchZero[path]
+	if state, value, ok := optionalOf(field); ok {
+		b.optional(state, value, path, explicit)
+		return
+	}
+	switch field.Kind() {
+	case reflect.Ptr:
+		if field.IsNil() {
+			if explicit {
+				b.add(path, nil)
+			}
+			return
+		}
+		if !explicit && nestedStruct(field.Type().Elem()) {
+			b.fields(field.Elem(), path)
+			return
+		}
+		b.add(path, field.Elem().Interface())
+		return
+	case reflect.Interface:
+		if field.IsNil() {
+			if explicit {
+				b.add(path, nil)
+			}
+			return
+		}
+	}
+	if explicit {
+		b.add(path, field.Interface())
+		return
+	}
+	if nestedStruct(field.Type()) {
+		b.fields(field, path)
+		return
+	}
+	if isZeroValue(field) {
+		return
+	}
+	b.add(path, field.Interface())
+}
+
+// optional 未设置的字段跳过 null 匹配或写入 null 有值时使用其值
+func (b *filterBuilder) optional(state optionalState, value any, path string, explicit bool) {
+	switch {
+	case state == optionalValue:
+		b.add(path, value)
+	case state == optionalUnset && !explicit:
+	case b.unsetNull:
+		b.unset = append(b.unset, bson.E{Key: path, Value: ""})
+	default:
+		b.add(path, nil)
+	}
+}
+
+func (b *filterBuilder) add(path string, value any) {
+	b.filter = append(b.filter, bson.E{Key: path, Value: value})
+}
+
+// nestedStruct 需要展开为点号路径的结构体 时间、ObjectID 等按值匹配的类型除外
+func nestedStruct(t reflect.Type) bool {
+	if t.Kind() != reflect.Struct {
+		return false
+	}
+	switch t {
+	case timeType, timestampType, objectIDType, decimal128Type:
+		return false
+	}
+	for _, marshaler := range []reflect.Type{valueMarshalerType, bsonMarshalerType} {
+		if t.Implements(marshaler) || reflect.PointerTo(t).Implements(marshaler) {
+			return false
+		}
+	}
+	return true
+}
+
+// isZeroValue 判断字段是否为零值 空切片与空 map 视为零值 实现 IsZero 的类型使用其判断结果
+func isZeroValue(field reflect.Value) bool {
+	switch field.Kind() {
+	case reflect.Slice, reflect.Map:
+		return field.Len() == 0
+	}
+	if field.Type().Implements(zeroCheckerType) {
+		return field.Interface().(interface{ IsZero() bool }).IsZero()
+	}
+	return field.IsZero()
+}
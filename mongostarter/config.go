@@ -0,0 +1,308 @@
+package mongostarter
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+var authMechanisms = []string{"SCRAM-SHA-1", "SCRAM-SHA-256", "MONGODB-X509", "MONGODB-AWS", "MONGODB-OIDC", "GSSAPI", "PLAIN"}
+
+// FieldError 单个配置字段的校验错误
+type FieldError struct {
+	Field string
+	Err   error
+}
+
+func (e *FieldError) Error() string {
+	return e.Field + ": " + e.Err.Error()
+}
+
+func (e *FieldError) Unwrap() error {
+	return e.Err
+}
+
+// ConfigError MongoConfig 校验错误 包含全部不合法的字段
+type ConfigError struct {
+	Fields []*FieldError
+}
+
+func (e *ConfigError) Error() string {
+	messages := make([]string, 0, len(e.Fields))
+	for _, field := range e.Fields {
+		messages = append(messages, field.Error())
+	}
+	return ErrInvalidMongoConfig.Error() + ": " + strings.Join(messages, "; ")
+}
+
+func (e *ConfigError) Is(target error) bool {
+	return target == ErrInvalidMongoConfig
+}
+
+func (e *ConfigError) Unwrap() []error {
+	result := make([]error, 0, len(e.Fields))
+	for _, field := range e.Fields {
+		result = append(result, field)
+	}
+	return result
+}
+
+func (e *ConfigError) add(field string, err error) {
+	e.Fields = append(e.Fields, &FieldError{Field: field, Err: err})
+}
+
+// redact 将错误信息中出现的密码替换为掩码
+func (c *MongoConfig) redact(message string) string {
+	secrets := []string{c.Password}
+	if parsed, err := url.Parse(c.MongoURI); err == nil && parsed.User != nil {
+		if password, ok := parsed.User.Password(); ok {
+			secrets = append(secrets, password)
+		}
+	}
+	for _, secret := range secrets {
+		if secret == "" {
+			continue
+		}
+		message = strings.ReplaceAll(message, secret, "******")
+		message = strings.ReplaceAll(message, url.QueryEscape(secret), "******")
+		message = strings.ReplaceAll(message, url.PathEscape(secret), "******")
+	}
+	return message
+}
+
+// redactError 错误信息中包含密码时返回脱敏后的错误 否则原样返回
+func (c *MongoConfig) redactError(err error) error {
+	if err == nil {
+		return nil
+	}
+	message := err.Error()
+	if redacted := c.redact(message); redacted != message {
+		return errors.New(redacted)
+	}
+	return err
+}
+
+// validate 校验连接配置与据此构建的客户端选项 返回包含全部不合法字段的 ConfigError
+// 配置了 TLS 时同时返回已加载证书的 reloader 不修改 clientOptions
+func (c *MongoConfig) validate(clientOptions *options.ClientOptions) (*tlsReloader, error) {
+	result := &ConfigError{}
+	if strings.TrimSpace(c.MongoURI) == "" && len(c.Hosts) == 0 {
+		result.add("MongoURI", ErrMongoURIRequired)
+	}
+	for i, host := range c.Hosts {
+		if err := validateHost(host); err != nil {
+			result.add(fmt.Sprintf("Hosts[%d]", i), err)
+		}
+	}
+	if c.Password != "" && c.Username == "" {
+		result.add("Username", errors.New("username is required when password is set"))
+	}
+	if c.AuthMechanism != "" {
+		mechanism := strings.ToUpper(c.AuthMechanism)
+		supported := false
+		for _, item := range authMechanisms {
+			if item == mechanism {
+				supported = true
+				break
+			}
+		}
+		if !supported {
+			result.add("AuthMechanism", fmt.Errorf("unsupported auth mechanism %q", c.AuthMechanism))
+		}
+	}
+	if c.DirectConnection != nil && *c.DirectConnection && len(c.Hosts) > 1 {
+		result.add("DirectConnection", errors.New("direct connection requires exactly one host"))
+	}
+	if c.ConnectTimeout < 0 {
+		result.add("ConnectTimeout", errors.New("must not be negative"))
+	}
+	if c.CredentialRefreshInterval < 0 {
+		result.add("CredentialRefreshInterval", errors.New("must not be negative"))
+	}
+	if c.Timeout < 0 {
+		result.add("Timeout", errors.New("must not be negative"))
+	}
+	if c.MaxPoolSize > 0 && c.MinPoolSize > c.MaxPoolSize {
+		result.add("MinPoolSize", errors.New("must not be greater than MaxPoolSize"))
+	}
+	if c.MaxConnIdleTime < 0 {
+		result.add("MaxConnIdleTime", errors.New("must not be negative"))
+	}
+	if c.ServerSelectionTimeout < 0 {
+		result.add("ServerSelectionTimeout", errors.New("must not be negative"))
+	}
+	if c.HeartbeatInterval < 0 {
+		result.add("HeartbeatInterval", errors.New("must not be negative"))
+	} else if c.HeartbeatInterval > 0 && c.HeartbeatInterval < 500*time.Millisecond {
+		result.add("HeartbeatInterval", errors.New("must not be less than 500ms"))
+	}
+	if c.WarmUpPool && c.MinPoolSize == 0 && (clientOptions.MinPoolSize == nil || *clientOptions.MinPoolSize == 0) {
+		result.add("WarmUpPool", errors.New("requires MinPoolSize greater than zero"))
+	}
+	var reloader *tlsReloader
+	if c.TLS != nil {
+		// 证书只加载一次 由连接流程使用同一个 reloader 建立拨号器
+		reloader = c.TLS.validate(result)
+	}
+	if c.ConditionMode != ConditionStruct && c.ConditionMode != ConditionNonZero {
+		result.add("ConditionMode", fmt.Errorf("unsupported condition mode %d", c.ConditionMode))
+	}
+	if c.UpdateMode != UpdateStruct && c.UpdateMode != UpdatePartial {
+		result.add("UpdateMode", fmt.Errorf("unsupported update mode %d", c.UpdateMode))
+	}
+	if c.Naming != nil {
+		c.Naming.validate(result)
+	}
+	if len(result.Fields) > 0 {
+		return nil, result
+	}
+	// 字段均合法时再由驱动校验 URI 解析结果与选项组合
+	if err := clientOptions.Validate(); err != nil {
+		if strings.TrimSpace(c.MongoURI) != "" {
+			result.add("MongoURI", fmt.Errorf("%w: %s", ErrInvalidMongoURI, c.redact(err.Error())))
+		} else {
+			result.add("Hosts", errors.New(c.redact(err.Error())))
+		}
+		return nil, result
+	}
+	return reloader, nil
+}
+
+func validateHost(host string) error {
+	host = strings.TrimSpace(host)
+	if host == "" {
+		return errors.New("host must not be empty")
+	}
+	name, port, err := net.SplitHostPort(host)
+	if err != nil {
+		// 未携带端口的主机名或方括号包裹的 IPv6 地址
+		if !strings.Contains(host, ":") || (strings.HasPrefix(host, "[") && strings.HasSuffix(host, "]")) {
+			return nil
+		}
+		return fmt.Errorf("invalid host %q", host)
+	}
+	if name == "" {
+		return fmt.Errorf("invalid host %q", host)
+	}
+	if value, err := strconv.Atoi(port); err != nil || value <= 0 || value > 65535 {
+		return fmt.Errorf("invalid port in host %q", host)
+	}
+	return nil
+}
+
+// applyConnection 应用连接配置 结构化字段非零值时覆盖 MongoURI 中的同名参数
+func (c *MongoConfig) applyConnection(clientOptions *options.ClientOptions) {
+	if strings.TrimSpace(c.MongoURI) != "" {
+		clientOptions.ApplyURI(c.MongoURI)
+	}
+	if len(c.Hosts) > 0 {
+		hosts := make([]string, 0, len(c.Hosts))
+		for _, host := range c.Hosts {
+			hosts = append(hosts, strings.TrimSpace(host))
+		}
+		clientOptions.SetHosts(hosts)
+	}
+	if c.Username != "" || c.Password != "" || c.AuthMechanism != "" || c.AuthSource != "" {
+		var credential options.Credential
+		if clientOptions.Auth != nil {
+			credential = *clientOptions.Auth
+		}
+		if c.Username != "" {
+			credential.Username = c.Username
+		}
+		if c.Password != "" {
+			credential.Password = c.Password
+			credential.PasswordSet = true
+		}
+		if c.AuthMechanism != "" {
+			credential.AuthMechanism = strings.ToUpper(c.AuthMechanism)
+		}
+		if c.AuthSource != "" {
+			credential.AuthSource = c.AuthSource
+		}
+		clientOptions.SetAuth(credential)
+	}
+	if c.ReplicaSet != "" {
+		clientOptions.SetReplicaSet(c.ReplicaSet)
+	}
+	if c.DirectConnection != nil {
+		clientOptions.SetDirect(*c.DirectConnection)
+	}
+	if c.ConnectTimeout > 0 {
+		clientOptions.SetConnectTimeout(c.ConnectTimeout)
+	}
+	if c.Timeout > 0 {
+		clientOptions.SetTimeout(c.Timeout)
+	}
+	if c.MaxPoolSize > 0 {
+		clientOptions.SetMaxPoolSize(c.MaxPoolSize)
+	}
+	if c.MinPoolSize > 0 {
+		clientOptions.SetMinPoolSize(c.MinPoolSize)
+	}
+	if c.MaxConnecting > 0 {
+		clientOptions.SetMaxConnecting(c.MaxConnecting)
+	}
+	if c.MaxConnIdleTime > 0 {
+		clientOptions.SetMaxConnIdleTime(c.MaxConnIdleTime)
+	}
+	if c.ServerSelectionTimeout > 0 {
+		clientOptions.SetServerSelectionTimeout(c.ServerSelectionTimeout)
+	}
+	if c.HeartbeatInterval > 0 {
+		clientOptions.SetHeartbeatInterval(c.HeartbeatInterval)
+	}
+}
+
+// withCredential 返回使用指定凭据的配置副本 credential 为 nil 时返回自身
+func (c *MongoConfig) withCredential(credential *Credential) *MongoConfig {
+	if credential == nil {
+		return c
+	}
+	config := *c
+	config.Username = credential.Username
+	config.Password = credential.Password
+	return &config
+}
+
+// clientOptions 构建并校验客户端选项 不包含监控器
+func (c *MongoConfig) clientOptions() (*options.ClientOptions, error) {
+	clientOptions := options.Client()
+	c.applyConnection(clientOptions)
+	reloader, err := c.validate(clientOptions)
+	if err != nil {
+		return nil, err
+	}
+	if reloader != nil {
+		// 由自定义拨号器按目标主机完成 TLS 握手 关闭驱动内置的 TLS
+		clientOptions.SetTLSConfig(nil).SetDialer(reloader.dialer())
+	}
+	if c.Compressors != nil {
+		clientOptions.SetCompressors(c.Compressors)
+	} else {
+		clientOptions.SetCompressors([]string{"zstd", "zlib", "snappy"})
+	}
+	if c.BSONOptions != nil {
+		clientOptions.SetBSONOptions(c.BSONOptions)
+	}
+	return clientOptions, nil
+}
+
+// resolveDatabase 获取默认数据库 Database 为空时从 MongoURI 的路径部分提取
+func (c *MongoConfig) resolveDatabase() (string, error) {
+	if c.Database != "" || strings.TrimSpace(c.MongoURI) == "" {
+		return c.Database, nil
+	}
+	parsedURI, err := url.Parse(c.MongoURI)
+	if err != nil {
+		return "", fmt.Errorf("%w: %s", ErrInvalidMongoURI, c.redact(err.Error()))
+	}
+	// 获取路径部分并去掉前导的 '/'
+	return strings.TrimPrefix(parsedURI.Path, "/"), nil
+}
@@ -0,0 +1,279 @@
+package mongostarter
+
+import (
+	"container/list"
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+	"strconv"
+	"sync"
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+const (
+	// 默认的分页总数缓存有效期
+	defaultCountCacheTTL = time.Minute
+	// 分页总数缓存的最大条目数
+	countCacheSize = 1024
+)
+
+// 分页总数缓存 停止时清空
+var pageCountCache = &countCache{entries: make(map[string]*list.Element)}
+
+// CountStrategy 分页查询统计总数的方式
+type CountStrategy int
+
+const (
+	// CountExact 使用 CountDocuments 精确统计
+	CountExact CountStrategy = iota
+	// CountEstimated 过滤条件为空时使用 EstimatedDocumentCount 读取集合元数据 否则精确统计
+	CountEstimated
+	// CountCapped 最多统计 CountLimit 条 达到上限时总数表示为 N+
+	CountCapped
+	// CountSkip 不统计总数 返回的总数为 -1
+	CountSkip
+	// CountCached 精确统计并按数据库、集合、过滤条件与统计选项缓存 CountCacheTTL 最多缓存 1024 个条目
+	CountCached
+)
+
+func (s CountStrategy) String() string {
+	switch s {
+	case CountExact:
+		return "exact"
+	case CountEstimated:
+		return "estimated"
+	case CountCapped:
+		return "capped"
+	case CountSkip:
+		return "skip"
+	case CountCached:
+		return "cached"
+	default:
+		return "CountStrategy(" + strconv.Itoa(int(s)) + ")"
+	}
+}
+
+// PageCount 分页查询的总数统计信息
+type PageCount struct {
+	// 实际使用的统计方式 CountEstimated 在过滤条件非空时为 CountExact
+	Strategy CountStrategy
+	// 总数 CountSkip 时为 -1
+	Total int64
+	// 达到 CountLimit 上限 实际总数不小于 Total
+	Capped bool
+	// 总数来自缓存
+	Cached bool
+}
+
+// String 总数的展示形式 如 120、1000+ 跳过统计时为 unknown
+func (c PageCount) String() string {
+	switch {
+	case c.Strategy == CountSkip:
+		return "unknown"
+	case c.Capped:
+		return strconv.FormatInt(c.Total, 10) + "+"
+	default:
+		return strconv.FormatInt(c.Total, 10)
+	}
+}
+
+// validate 校验分页参数与统计方式
+func (q PageQuery) validate() error {
+	if q.PageNumber <= 0 || q.PageSize <= 0 {
+		return ErrInvalidPage
+	}
+	switch q.CountStrategy {
+	case CountExact, CountEstimated, CountSkip:
+	case CountCapped:
+		if q.CountLimit <= 0 {
+			return fmt.Errorf("%w: count limit must be greater than zero", ErrInvalidPage)
+		}
+	case CountCached:
+		if q.CountCacheTTL < 0 {
+			return fmt.Errorf("%w: count cache TTL must not be negative", ErrInvalidPage)
+		}
+	default:
+		return fmt.Errorf("%w: unsupported count strategy %s", ErrInvalidPage, q.CountStrategy)
+	}
+	return nil
+}
+
+// countPage 按 PageQuery 的统计方式统计总数
+func countPage(ctx context.Context, coll *mongo.Collection, filter any, query PageQuery, opts ...options.Lister[options.CountOptions]) (result PageCount, err error) {
+	result.Strategy = query.CountStrategy
+	switch query.CountStrategy {
+	case CountSkip:
+		result.Total = -1
+	case CountEstimated:
+		var empty bool
+		if empty, err = isEmptyCondition(filter); err == nil && empty {
+			result.Total, err = coll.EstimatedDocumentCount(ctx)
+		} else if err == nil {
+			result.Strategy = CountExact
+			result.Total, err = coll.CountDocuments(ctx, filter, opts...)
+		}
+	case CountCapped:
+		opts = append(opts, options.Count().SetLimit(query.CountLimit))
+		if result.Total, err = coll.CountDocuments(ctx, filter, opts...); err == nil {
+			result.Capped = result.Total >= query.CountLimit
+		}
+	case CountCached:
+		result.Total, result.Cached, err = cachedCount(ctx, coll, filter, query.CountCacheTTL, opts...)
+	default:
+		result.Total, err = coll.CountDocuments(ctx, filter, opts...)
+	}
+	if err != nil {
+		return PageCount{}, err
+	}
+	return result, nil
+}
+
+// cachedCount 读取或写入分页总数缓存 过滤条件或统计选项无法编码为 Extended JSON 时不缓存
+func cachedCount(ctx context.Context, coll *mongo.Collection, filter any, ttl time.Duration, opts ...options.Lister[options.CountOptions]) (total int64, cached bool, err error) {
+	if ttl == 0 {
+		ttl = defaultCountCacheTTL
+	}
+	key, err := countCacheKey(coll, filter, opts)
+	if err != nil {
+		total, err = coll.CountDocuments(ctx, filter, opts...)
+		return total, false, err
+	}
+	if total, ok := pageCountCache.get(key); ok {
+		return total, true, nil
+	}
+	if total, err = coll.CountDocuments(ctx, filter, opts...); err != nil {
+		return 0, false, err
+	}
+	pageCountCache.put(key, total, ttl)
+	return total, false, nil
+}
+
+// countCacheKey 由数据库、集合、规范化的过滤条件以及统计选项生成缓存键
+func countCacheKey(coll *mongo.Collection, filter any, opts []options.Lister[options.CountOptions]) (string, error) {
+	countOptions := &options.CountOptions{}
+	for _, opt := range opts {
+		if opt == nil {
+			continue
+		}
+		for _, set := range opt.List() {
+			if err := set(countOptions); err != nil {
+				return "", err
+			}
+		}
+	}
+	var collation bson.D
+	if countOptions.Collation != nil {
+		collation = collationDocument(countOptions.Collation)
+	}
+	document := bson.D{
+		{Key: "filter", Value: canonicalValue(filter)},
+		{Key: "collation", Value: collation},
+		{Key: "hint", Value: canonicalValue(countOptions.Hint)},
+		{Key: "limit", Value: countOptions.Limit},
+		{Key: "skip", Value: countOptions.Skip},
+	}
+	data, err := bson.MarshalExtJSON(document, true, false)
+	if err != nil {
+		return "", err
+	}
+	return coll.Database().Name() + "." + coll.Name() + "|" + string(data), nil
+}
+
+// canonicalValue 将 map 按键排序转换为 bson.D 使同一过滤条件编码结果稳定 bson.D 与数组保持原有顺序
+func canonicalValue(value any) any {
+	switch v := value.(type) {
+	case bson.M:
+		return canonicalMap(v)
+	case map[string]any:
+		return canonicalMap(v)
+	case bson.D:
+		result := make(bson.D, len(v))
+		for i, e := range v {
+			result[i] = bson.E{Key: e.Key, Value: canonicalValue(e.Value)}
+		}
+		return result
+	case bson.A:
+		result := make(bson.A, len(v))
+		for i, item := range v {
+			result[i] = canonicalValue(item)
+		}
+		return result
+	case []any:
+		result := make(bson.A, len(v))
+		for i, item := range v {
+			result[i] = canonicalValue(item)
+		}
+		return result
+	default:
+		return value
+	}
+}
+
+func canonicalMap(m map[string]any) bson.D {
+	keys := slices.Sorted(maps.Keys(m))
+	result := make(bson.D, 0, len(keys))
+	for _, key := range keys {
+		result = append(result, bson.E{Key: key, Value: canonicalValue(m[key])})
+	}
+	return result
+}
+
+type countCacheEntry struct {
+	key     string
+	total   int64
+	expires time.Time
+}
+
+// countCache 带有效期与容量上限的分页总数缓存 超出容量时淘汰最久未使用的条目
+type countCache struct {
+	mu      sync.Mutex
+	order   list.List
+	entries map[string]*list.Element
+}
+
+func (c *countCache) get(key string) (int64, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	element, ok := c.entries[key]
+	if !ok {
+		return 0, false
+	}
+	entry := element.Value.(*countCacheEntry)
+	if time.Now().After(entry.expires) {
+		c.order.Remove(element)
+		delete(c.entries, key)
+		return 0, false
+	}
+	c.order.MoveToFront(element)
+	return entry.total, true
+}
+
+func (c *countCache) put(key string, total int64, ttl time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	expires := time.Now().Add(ttl)
+	if element, ok := c.entries[key]; ok {
+		entry := element.Value.(*countCacheEntry)
+		entry.total, entry.expires = total, expires
+		c.order.MoveToFront(element)
+		return
+	}
+	c.entries[key] = c.order.PushFront(&countCacheEntry{key: key, total: total, expires: expires})
+	for c.order.Len() > countCacheSize {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*countCacheEntry).key)
+	}
+}
+
+func (c *countCache) clear() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.order.Init()
+	clear(c.entries)
+}
@@ -0,0 +1,235 @@
+package mongostarter
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/acexy/golang-toolkit/logger"
+	"go.mongodb.org/mongo-driver/v2/event"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+const (
+	// 认证失败触发轮换的最小间隔 避免凭据尚未更新时反复重建客户端
+	authFailureRotateInterval = 10 * time.Second
+	// 旧客户端等待进行中操作结束的最长时间
+	credentialDrainTimeout = time.Minute
+	// 服务端返回的认证失败错误码
+	authenticationFailedCode = 18
+)
+
+var mongoCredentials *credentialRotator
+
+// Credential 认证凭据
+type Credential struct {
+	Username string
+	Password string
+}
+
+// CredentialProvider 凭据提供函数 返回当前有效的凭据 通常从密钥管理服务读取
+type CredentialProvider func(ctx context.Context) (Credential, error)
+
+// credentialRotator 凭据轮换 凭据变化时构建新客户端替换当前客户端 并在旧客户端的操作结束后断开
+type credentialRotator struct {
+	config   *MongoConfig
+	startup  StartupConfig
+	monitors *clientMonitors
+
+	ctx      context.Context
+	cancel   context.CancelFunc
+	rotating atomic.Bool
+	// 旧客户端排空使用独立的上下文 停止时先等待排空 超时后才强制断开
+	drainCtx    context.Context
+	cancelDrain context.CancelFunc
+	draining    sync.WaitGroup
+
+	mu          sync.Mutex
+	current     Credential
+	authFailure time.Time
+}
+
+func newCredentialRotator(config *MongoConfig, startup StartupConfig, monitors *clientMonitors) *credentialRotator {
+	ctx, cancel := context.WithCancel(context.Background())
+	drainCtx, cancelDrain := context.WithCancel(context.Background())
+	return &credentialRotator{
+		config:      config,
+		startup:     startup,
+		monitors:    monitors,
+		ctx:         ctx,
+		cancel:      cancel,
+		drainCtx:    drainCtx,
+		cancelDrain: cancelDrain,
+	}
+}
+
+// fetch 调用凭据提供函数 超时时间与启动时的 PingTimeout 一致
+func (r *credentialRotator) fetch(ctx context.Context) (Credential, error) {
+	ctx, cancel := context.WithTimeout(ctx, r.startup.PingTimeout)
+	defer cancel()
+	credential, err := r.config.CredentialProvider(ctx)
+	if err != nil {
+		return Credential{}, fmt.Errorf("%w: %w", ErrCredentialProvider, err)
+	}
+	return credential, nil
+}
+
+// start 记录当前使用的凭据 配置了 CredentialRefreshInterval 时开始定时轮换
+func (r *credentialRotator) start(current Credential) {
+	r.mu.Lock()
+	r.current = current
+	r.mu.Unlock()
+	interval := r.config.CredentialRefreshInterval
+	if interval <= 0 {
+		return
+	}
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-r.ctx.Done():
+				return
+			case <-ticker.C:
+				r.trigger()
+			}
+		}
+	}()
+}
+
+// stop 停止轮换并等待旧客户端排空 ctx 结束时立即断开仍在排空的旧客户端
+func (r *credentialRotator) stop(ctx context.Context) {
+	r.cancel()
+	done := make(chan struct{})
+	go func() {
+		r.draining.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-ctx.Done():
+		r.cancelDrain()
+		<-done
+	}
+}
+
+// monitor 连接握手认证失败时触发轮换
+func (r *credentialRotator) monitor() *event.PoolMonitor {
+	if r == nil {
+		return nil
+	}
+	return &event.PoolMonitor{
+		Event: func(evt *event.PoolEvent) {
+			switch evt.Type {
+			case event.ConnectionClosed, event.ConnectionCheckOutFailed:
+				if evt.Error != nil && isAuthError(evt.Error) {
+					r.onAuthFailure()
+				}
+			}
+		},
+	}
+}
+
+// onAuthFailure 认证失败时触发轮换 同一间隔内只触发一次
+func (r *credentialRotator) onAuthFailure() {
+	r.mu.Lock()
+	if time.Since(r.authFailure) < authFailureRotateInterval {
+		r.mu.Unlock()
+		return
+	}
+	r.authFailure = time.Now()
+	r.mu.Unlock()
+	r.trigger()
+}
+
+// trigger 在后台执行一次轮换 已有轮换进行中时忽略
+func (r *credentialRotator) trigger() {
+	if r.ctx.Err() != nil || !r.rotating.CompareAndSwap(false, true) {
+		return
+	}
+	go func() {
+		defer r.rotating.Store(false)
+		if err := r.rotate(); err != nil && r.ctx.Err() == nil {
+			logger.Logrus().WithError(err).Warnln("mongo credential rotation failed, keep using the current client")
+		}
+	}()
+}
+
+// rotate 获取最新凭据 有变化时构建并验证新客户端 替换当前客户端后排空旧客户端
+func (r *credentialRotator) rotate() error {
+	credential, err := r.fetch(r.ctx)
+	if err != nil {
+		return err
+	}
+	r.mu.Lock()
+	unchanged := credential == r.current
+	r.mu.Unlock()
+	if unchanged {
+		return nil
+	}
+	config := r.config.withCredential(&credential)
+	state, _, err := dial(config, r.monitors)
+	if err != nil {
+		return err
+	}
+	if err = r.startup.pingWithRetry(r.ctx, state.client, r.startup.MaxAttempts, config); err == nil {
+		err = r.startup.warmUp(r.ctx, state.client, state.pool, state.warmUp)
+	}
+	if err != nil {
+		_ = state.client.Disconnect(context.Background())
+		return config.redactError(err)
+	}
+
+	mongoLock.Lock()
+	if r.ctx.Err() != nil || mongoClient == nil || mongoStopping {
+		mongoLock.Unlock()
+		_ = state.client.Disconnect(context.Background())
+		return r.ctx.Err()
+	}
+	old := &clientState{client: mongoClient, inflight: mongoInflight}
+	publish(state)
+	r.draining.Add(1)
+	mongoLock.Unlock()
+
+	r.mu.Lock()
+	r.current = credential
+	r.mu.Unlock()
+	logger.Logrus().Infoln("mongo credentials rotated, client replaced")
+	go r.drain(old)
+	return nil
+}
+
+// drain 等待旧客户端上进行中的操作结束后断开连接
+func (r *credentialRotator) drain(old *clientState) {
+	defer r.draining.Done()
+	ctx, cancel := context.WithTimeout(r.drainCtx, credentialDrainTimeout)
+	defer cancel()
+	if running := old.inflight.wait(ctx); running > 0 {
+		logger.Logrus().Warnf("disconnecting previous mongo client with %d operations still running", running)
+	}
+	ctx, cancel = context.WithTimeout(context.Background(), r.startup.PingTimeout)
+	defer cancel()
+	if err := old.client.Disconnect(ctx); err != nil {
+		logger.Logrus().WithError(err).Warnln("disconnect previous mongo client failed")
+	}
+}
+
+// notifyAuthFailure Mapper 操作认证失败时通知凭据轮换
+func notifyAuthFailure() {
+	mongoLock.RLock()
+	rotator := mongoCredentials
+	mongoLock.RUnlock()
+	if rotator != nil {
+		rotator.onAuthFailure()
+	}
+}
+
+// isAuthError 判断错误是否由认证失败引起
+// 握手中的认证失败由驱动包装为带错误码的 mongo.CommandError 因此无需依赖 x/ 包中的认证错误类型
+func isAuthError(err error) bool {
+	var serverErr mongo.ServerError
+	return errors.As(err, &serverErr) && serverErr.HasErrorCode(authenticationFailedCode)
+}
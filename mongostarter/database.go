@@ -0,0 +1,38 @@
+package mongostarter
+
+import "go.mongodb.org/mongo-driver/v2/mongo"
+
+// DatabaseModel 模型实现该接口声明所在数据库 未实现时使用默认数据库
+type DatabaseModel interface {
+	Model
+	DatabaseName() string
+}
+
+// WithDatabase 返回绑定指定数据库的 Mapper 副本 覆盖模型声明的数据库 空字符串恢复模型声明或默认数据库
+func (b BaseMapper[T]) WithDatabase(database string) BaseMapper[T] {
+	b.database = database
+	return b
+}
+
+// databaseName 按 Mapper、模型的顺序解析数据库 为空时表示默认数据库
+func (b BaseMapper[T]) databaseName() string {
+	if b.database != "" {
+		return b.database
+	}
+	return modelDatabaseName(b.model)
+}
+
+func modelDatabaseName(model Model) string {
+	if declared, ok := model.(DatabaseModel); ok {
+		return declared.DatabaseName()
+	}
+	return ""
+}
+
+// modelDatabase 获取模型所在数据库 模型未声明时返回 database
+func modelDatabase(database *mongo.Database, model Model) *mongo.Database {
+	if name := modelDatabaseName(model); name != "" && name != database.Name() {
+		return database.Client().Database(name)
+	}
+	return database
+}
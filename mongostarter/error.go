@@ -5,12 +5,28 @@ import "errors"
 var (
 	ErrMongoStarterAlreadyStarted = errors.New("mongo starter already started")
 	ErrMongoStarterNotStarted     = errors.New("mongo starter not started")
+	ErrMongoStarterStopping       = errors.New("mongo starter is stopping, new operations are rejected")
 	ErrMongoStopTimeout           = errors.New("waiting for mongo starter shutdown timeout")
 	ErrEmptyIDs                   = errors.New("ids must not be empty")
 	ErrEmptyCondition             = errors.New("condition must not be empty")
 	ErrInvalidPage                = errors.New("page number and page size must be greater than zero")
 	ErrNotAcknowledged            = errors.New("mongo operation was not acknowledged")
-	ErrMongoURIRequired           = errors.New("mongo URI is required")
+	ErrMongoURIRequired           = errors.New("mongo URI or hosts are required")
 	ErrMongoDatabaseRequired      = errors.New("mongo database is required")
 	ErrInvalidMongoURI            = errors.New("invalid mongo URI")
+	ErrInvalidMongoConfig         = errors.New("invalid mongo config")
+	ErrPoolWarmUpTimeout          = errors.New("mongo connection pool warm-up timeout")
+	ErrMetricsNotEnabled          = errors.New("mongo metrics not enabled")
+	ErrCredentialProvider         = errors.New("mongo credential provider failed")
+	ErrInvalidIndex               = errors.New("invalid index declaration")
+	ErrInvalidSchema              = errors.New("invalid schema declaration")
+	ErrInvalidCollectionOptions   = errors.New("invalid collection options")
+	ErrEmptyUpdate                = errors.New("update document is empty")
+	ErrInvalidFieldMask           = errors.New("invalid field mask")
+	ErrNotTracked                 = errors.New("entity is not tracked")
+	ErrVersionConflict            = errors.New("document version conflict")
+	ErrVersionPath                = errors.New("changed field contains the version field")
+	ErrInvalidGroupQuery          = errors.New("invalid group query")
+	ErrInvalidProjection          = errors.New("specify columns and exclude columns cannot be combined")
+	ErrInvalidAcrossQuery         = errors.New("invalid across query")
 )
@@ -0,0 +1,87 @@
+package mongostarter
+
+import (
+	"reflect"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+// 与客户端 BSONOptions.UseJSONStructTags 保持一致 缺少 bson 标签时使用 json 标签
+var useJSONStructTags bool
+
+// 当前客户端的 BSON 选项 受 mongoLock 保护
+var bsonOptions *options.BSONOptions
+
+// structField 结构体字段的 bson 编码信息
+type structField struct {
+	// 文档中的字段名
+	name string
+	// 展开到父文档
+	inline    bool
+	omitEmpty bool
+}
+
+// jsonStructTags 当前客户端是否使用 json 标签作为后备
+func jsonStructTags() bool {
+	mongoLock.RLock()
+	defer mongoLock.RUnlock()
+	return useJSONStructTags
+}
+
+// currentBSONOptions 当前客户端的 BSON 选项 未设置时返回 nil
+func currentBSONOptions() *options.BSONOptions {
+	mongoLock.RLock()
+	defer mongoLock.RUnlock()
+	return bsonOptions
+}
+
+// parseStructField 按驱动规则解析字段的 bson 标签 ok 为 false 表示字段不参与编码
+// 驱动默认不编码未导出字段 未导出类型的匿名字段同样跳过 导出类型的匿名字段 PkgPath 为空
+func parseStructField(field reflect.StructField, jsonTags bool) (result structField, ok bool) {
+	if field.PkgPath != "" {
+		return result, false
+	}
+	tag, found := field.Tag.Lookup("bson")
+	if !found && jsonTags {
+		tag, found = field.Tag.Lookup("json")
+	}
+	if !found && !strings.Contains(string(field.Tag), ":") && len(field.Tag) > 0 {
+		tag = string(field.Tag)
+	}
+	if tag == "-" {
+		return result, false
+	}
+	result.name = strings.ToLower(field.Name)
+	for i, part := range strings.Split(tag, ",") {
+		if i == 0 && part != "" {
+			result.name = part
+		}
+		switch part {
+		case "omitempty":
+			result.omitEmpty = true
+		case "inline":
+			result.inline = true
+		}
+	}
+	return result, true
+}
+
+// structType 解引用指针后返回结构体类型 非结构体时返回 nil
+func structType(t reflect.Type) reflect.Type {
+	for t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t == nil || t.Kind() != reflect.Struct {
+		return nil
+	}
+	return t
+}
+
+// joinPath 拼接点号路径
+func joinPath(prefix, name string) string {
+	if prefix == "" {
+		return name
+	}
+	return prefix + "." + name
+}
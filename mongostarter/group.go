@@ -0,0 +1,167 @@
+package mongostarter
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strconv"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+// GroupSort 分组统计结果的排序方式
+type GroupSort int
+
+const (
+	// GroupSortCountDesc 按数量降序 数量相同时按分组值升序
+	GroupSortCountDesc GroupSort = iota
+	// GroupSortCountAsc 按数量升序 数量相同时按分组值升序
+	GroupSortCountAsc
+	// GroupSortValueAsc 按分组值升序
+	GroupSortValueAsc
+	// GroupSortValueDesc 按分组值降序
+	GroupSortValueDesc
+)
+
+// GroupQuery 分组统计参数
+type GroupQuery struct {
+	// 分组字段 多个字段时按字段值组合分组 嵌套字段使用点号路径
+	Fields []string
+	// 排序方式 默认按数量降序
+	Sort GroupSort
+	// 只返回前 N 组 0 表示全部
+	Limit int
+}
+
+// GroupCount 一个分组的统计结果
+type GroupCount struct {
+	// 分组字段值 与 GroupQuery.Fields 顺序一致 缺失字段为 nil
+	Values []any
+	Count  int64
+}
+
+// Value 第一个分组字段的值 用于单字段分组
+func (g *GroupCount) Value() any {
+	if len(g.Values) == 0 {
+		return nil
+	}
+	return g.Values[0]
+}
+
+// GroupCounts 按排序方式排列的分组统计结果
+type GroupCounts []*GroupCount
+
+// Map 转换为分组值到数量的映射 用于单字段分组 数组、文档等不可比较的值使用其字符串形式作为键
+func (g GroupCounts) Map() map[any]int64 {
+	result := make(map[any]int64, len(g))
+	for _, group := range g {
+		key := group.Value()
+		if key != nil && !reflect.TypeOf(key).Comparable() {
+			key = fmt.Sprint(key)
+		}
+		result[key] += group.Count
+	}
+	return result
+}
+
+// Distinct 查询字段的去重值并解码为 V 数组字段按元素去重
+// condition 可以是 *T 实体条件、bson.M 或其他过滤文档 nil 表示全部文档
+func Distinct[V any, T Model](mapper BaseMapper[T], field string, condition any) (values []V, err error) {
+	filter := mapper.filter(condition)
+	err = mapper.execute("Distinct", func(ctx context.Context, coll *mongo.Collection) (int64, error) {
+		result := coll.Distinct(ctx, field, filter)
+		if err := result.Err(); err != nil {
+			return 0, err
+		}
+		if err := result.Decode(&values); err != nil {
+			return 0, err
+		}
+		return int64(len(values)), nil
+	})
+	return values, err
+}
+
+// CountGroupBy 按字段分组统计数据数量
+// condition 可以是 *T 实体条件、bson.M 或其他过滤文档 nil 表示全部文档
+func (b BaseMapper[T]) CountGroupBy(condition any, query GroupQuery) (counts GroupCounts, err error) {
+	if len(query.Fields) == 0 {
+		return nil, fmt.Errorf("%w: fields must not be empty", ErrInvalidGroupQuery)
+	}
+	if query.Limit < 0 {
+		return nil, fmt.Errorf("%w: limit must not be negative", ErrInvalidGroupQuery)
+	}
+	sort, err := query.sort()
+	if err != nil {
+		return nil, err
+	}
+	// 分组键使用字段序号 字段路径中的 . 不能作为表达式对象的键
+	key := make(bson.D, 0, len(query.Fields))
+	for i, field := range query.Fields {
+		key = append(key, bson.E{Key: "f" + strconv.Itoa(i), Value: "$" + field})
+	}
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: b.filter(condition)}},
+		{{Key: "$group", Value: bson.D{{Key: "_id", Value: key}, {Key: "count", Value: bson.D{{Key: "$sum", Value: 1}}}}}},
+		{{Key: "$sort", Value: sort}},
+	}
+	if query.Limit > 0 {
+		pipeline = append(pipeline, bson.D{{Key: "$limit", Value: query.Limit}})
+	}
+	err = b.execute("CountGroupBy", func(ctx context.Context, coll *mongo.Collection) (int64, error) {
+		cursor, err := coll.Aggregate(ctx, pipeline)
+		if err != nil {
+			return 0, err
+		}
+		defer cursor.Close(ctx)
+		for cursor.Next(ctx) {
+			var group struct {
+				ID    bson.Raw `bson:"_id"`
+				Count int64    `bson:"count"`
+			}
+			if err = cursor.Decode(&group); err != nil {
+				return 0, err
+			}
+			values := make([]any, len(query.Fields))
+			for i := range values {
+				if values[i], err = groupValue(group.ID, "f"+strconv.Itoa(i)); err != nil {
+					return 0, err
+				}
+			}
+			counts = append(counts, &GroupCount{Values: values, Count: group.Count})
+		}
+		return int64(len(counts)), cursor.Err()
+	})
+	if err != nil {
+		return nil, err
+	}
+	return counts, nil
+}
+
+func (q GroupQuery) sort() (bson.D, error) {
+	switch q.Sort {
+	case GroupSortCountDesc:
+		return bson.D{{Key: "count", Value: -1}, {Key: "_id", Value: 1}}, nil
+	case GroupSortCountAsc:
+		return bson.D{{Key: "count", Value: 1}, {Key: "_id", Value: 1}}, nil
+	case GroupSortValueAsc:
+		return bson.D{{Key: "_id", Value: 1}}, nil
+	case GroupSortValueDesc:
+		return bson.D{{Key: "_id", Value: -1}}, nil
+	default:
+		return nil, fmt.Errorf("%w: unsupported sort %d", ErrInvalidGroupQuery, q.Sort)
+	}
+}
+
+// groupValue 解码分组键中的字段值 缺失字段与 null 为 nil
+func groupValue(id bson.Raw, key string) (any, error) {
+	raw, err := id.LookupErr(key)
+	if err != nil || raw.Type == bson.TypeNull {
+		return nil, nil
+	}
+	var value any
+	if err = raw.Unmarshal(&value); err != nil {
+		return nil, err
+	}
+	return value, nil
+}
@@ -0,0 +1,124 @@
+package mongostarter
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sync"
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo/readpref"
+)
+
+// HealthStatus 健康检查结果
+type HealthStatus struct {
+	// Ping 是否成功
+	Healthy bool `json:"healthy"`
+	// 检查失败原因
+	Error string `json:"error,omitempty"`
+	// Ping 耗时 单位纳秒
+	PingLatency time.Duration `json:"pingLatency"`
+	// 拓扑类型 如 Single、ReplicaSetWithPrimary、Sharded
+	TopologyKind string `json:"topologyKind"`
+	// 是否存在可写的主节点
+	PrimaryAvailable bool `json:"primaryAvailable"`
+	// 服务端版本
+	ServerVersion string `json:"serverVersion,omitempty"`
+	// 各服务端地址的连接池统计
+	Pools []PoolStats `json:"pools"`
+	// 检查时间
+	CheckedAt time.Time `json:"checkedAt"`
+}
+
+// Health 检查 MongoDB 的连通性与运行状态
+// Ping 失败时同时返回已收集的状态与错误
+func Health(ctx context.Context) (*HealthStatus, error) {
+	mongoLock.RLock()
+	client, topology, pool := mongoClient, mongoTopology, mongoPool
+	mongoLock.RUnlock()
+	if client == nil {
+		return nil, ErrMongoStarterNotStarted
+	}
+	status := &HealthStatus{CheckedAt: time.Now()}
+	description := topology.current()
+	status.TopologyKind = description.Kind
+	status.PrimaryAvailable = primaryAvailable(description)
+	status.Pools = pool.snapshot()
+
+	start := time.Now()
+	err := client.Ping(ctx, readpref.PrimaryPreferred())
+	status.PingLatency = time.Since(start)
+	if err != nil {
+		status.Error = err.Error()
+		return status, err
+	}
+	status.Healthy = true
+
+	var buildInfo struct {
+		Version string `bson:"version"`
+	}
+	if err = client.Database("admin").RunCommand(ctx, bson.D{{Key: "buildInfo", Value: 1}}).Decode(&buildInfo); err == nil {
+		status.ServerVersion = buildInfo.Version
+	}
+	return status, nil
+}
+
+// HealthHandlerConfig 健康检查 Handler 配置
+type HealthHandlerConfig struct {
+	// 探测结果缓存时间 默认 5s 避免频繁的探针请求压到集群
+	CacheInterval time.Duration
+	// 单次探测超时时间 默认 3s
+	Timeout time.Duration
+	// 是否要求主节点可用 适用于 readiness 探针
+	RequirePrimary bool
+}
+
+type healthHandler struct {
+	config HealthHandlerConfig
+
+	mu     sync.Mutex
+	status *HealthStatus
+	err    error
+}
+
+// NewHealthHandler 创建输出 JSON 格式健康状态的 Handler
+// 健康时返回 200 否则返回 503
+func NewHealthHandler(config HealthHandlerConfig) http.Handler {
+	if config.CacheInterval <= 0 {
+		config.CacheInterval = 5 * time.Second
+	}
+	if config.Timeout <= 0 {
+		config.Timeout = 3 * time.Second
+	}
+	return &healthHandler{config: config}
+}
+
+// probe 获取缓存的探测结果 过期后重新探测
+// 使用独立的 context 避免单个请求取消导致失败结果被缓存
+func (h *healthHandler) probe() (*HealthStatus, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.status != nil && time.Since(h.status.CheckedAt) < h.config.CacheInterval {
+		return h.status, h.err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), h.config.Timeout)
+	defer cancel()
+	status, err := Health(ctx)
+	if status == nil {
+		status = &HealthStatus{Error: err.Error(), CheckedAt: time.Now()}
+	}
+	h.status, h.err = status, err
+	return status, err
+}
+
+func (h *healthHandler) ServeHTTP(w http.ResponseWriter, _ *http.Request) {
+	status, err := h.probe()
+	code := http.StatusOK
+	if err != nil || (h.config.RequirePrimary && !status.PrimaryAvailable) {
+		code = http.StatusServiceUnavailable
+	}
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(code)
+	_ = json.NewEncoder(w).Encode(status)
+}
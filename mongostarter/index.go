@@ -0,0 +1,465 @@
+package mongostarter
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/acexy/golang-toolkit/logger"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+// 集合不存在时服务端返回的错误码
+const namespaceNotFoundCode = 26
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// Index 索引声明
+type Index struct {
+	// 索引名称 为空时按驱动规则生成 如 hostname_1_pid_-1
+	Name string
+	// 索引键 值为 1、-1、"text" 或 "2dsphere"
+	Keys bson.D
+	// 唯一索引
+	Unique bool
+	// 稀疏索引
+	Sparse bool
+	// 大于 0 时为 TTL 索引 精度为秒
+	ExpireAfter time.Duration
+	// 部分索引的过滤条件 建议使用 bson.D 以保持字段顺序
+	PartialFilter any
+	// 索引排序规则
+	Collation *options.Collation
+}
+
+// IndexedModel 模型实现该接口声明索引 与 index 标签声明的索引合并
+type IndexedModel interface {
+	Model
+	Indexes() []Index
+}
+
+// IndexSyncConfig 索引同步配置
+type IndexSyncConfig struct {
+	// 需要同步索引的模型
+	Models []Model
+	// 删除未声明的索引 _id 索引除外
+	Prune bool
+}
+
+// IndexDiff 已存在但与声明不一致的索引
+type IndexDiff struct {
+	// 声明的索引名称
+	Name string
+	// 不一致的原因
+	Reason string
+}
+
+// IndexReport 单个集合的索引同步结果
+type IndexReport struct {
+	// 集合名称
+	Collection string
+	// 新建的索引
+	Created []string
+	// 与声明不一致的索引 不会被修改
+	Different []IndexDiff
+	// 未声明的索引 Prune 时被删除
+	Undeclared []string
+	// Prune 时删除的索引
+	Dropped []string
+}
+
+// EnsureIndexes 按模型声明同步索引 创建缺失的索引 报告不一致的索引 仅在 Prune 时删除未声明的索引
+func EnsureIndexes(ctx context.Context, config IndexSyncConfig) ([]IndexReport, error) {
+	database := RawDatabase()
+	if database == nil {
+		return nil, ErrMongoStarterNotStarted
+	}
+	return syncIndexes(ctx, database, config, jsonStructTags(), currentNaming())
+}
+
+func syncIndexes(ctx context.Context, database *mongo.Database, config IndexSyncConfig, jsonTags bool, naming *NamingStrategy) ([]IndexReport, error) {
+	reports := make([]IndexReport, 0, len(config.Models))
+	for _, model := range config.Models {
+		indexes, err := declaredIndexes(model, jsonTags)
+		if err != nil {
+			return reports, err
+		}
+		report, err := syncCollectionIndexes(ctx, modelDatabase(database, model).Collection(modelCollectionName(model, naming)), indexes, config.Prune)
+		if err != nil {
+			return reports, err
+		}
+		for _, diff := range report.Different {
+			logger.Logrus().Warnf("mongo index %s.%s differs from declaration: %s", report.Collection, diff.Name, diff.Reason)
+		}
+		reports = append(reports, report)
+	}
+	return reports, nil
+}
+
+// declaredIndexes 合并 index 标签与 IndexedModel 声明的索引 并补全索引名称
+func declaredIndexes(model Model, jsonTags bool) ([]Index, error) {
+	indexes, err := taggedIndexes(reflect.TypeOf(model), jsonTags)
+	if err != nil {
+		return nil, err
+	}
+	if indexed, ok := model.(IndexedModel); ok {
+		indexes = append(indexes, indexed.Indexes()...)
+	}
+	names := make(map[string]bool, len(indexes))
+	for i := range indexes {
+		if len(indexes[i].Keys) == 0 {
+			return nil, fmt.Errorf("%w: %s index %q has no keys", ErrInvalidIndex, baseCollectionName(model, NameCaseKeep), indexes[i].Name)
+		}
+		if indexes[i].Name == "" {
+			indexes[i].Name = indexName(indexes[i].Keys)
+		}
+		if names[indexes[i].Name] {
+			return nil, fmt.Errorf("%w: %s index %q is declared twice", ErrInvalidIndex, baseCollectionName(model, NameCaseKeep), indexes[i].Name)
+		}
+		names[indexes[i].Name] = true
+	}
+	return indexes, nil
+}
+
+// taggedIndexes 解析 index 标签 格式为 index:"[名称][,unique][,sparse][,desc][,text][,2dsphere][,ttl=24h]"
+// 名称相同的字段按声明顺序组成复合索引
+func taggedIndexes(t reflect.Type, jsonTags bool) ([]Index, error) {
+	var indexes []Index
+	positions := make(map[string]int)
+	var walk func(t reflect.Type, prefix string) error
+	walk = func(t reflect.Type, prefix string) error {
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			info, ok := parseStructField(field, jsonTags)
+			if !ok {
+				continue
+			}
+			path := prefix
+			if !info.inline {
+				path = joinPath(prefix, info.name)
+			}
+			tag, tagged := field.Tag.Lookup("index")
+			if !tagged {
+				if nested := structType(field.Type); nested != nil && nested != timeType {
+					if err := walk(nested, path); err != nil {
+						return err
+					}
+				}
+				continue
+			}
+			parts := strings.Split(tag, ",")
+			declared := Index{Name: parts[0]}
+			var value any = 1
+			for _, option := range parts[1:] {
+				switch {
+				case option == "unique":
+					declared.Unique = true
+				case option == "sparse":
+					declared.Sparse = true
+				case option == "desc":
+					value = -1
+				case option == "text" || option == "2dsphere":
+					value = option
+				case strings.HasPrefix(option, "ttl="):
+					ttl, err := time.ParseDuration(strings.TrimPrefix(option, "ttl="))
+					if err != nil {
+						return fmt.Errorf("%w: field %s: %v", ErrInvalidIndex, field.Name, err)
+					}
+					declared.ExpireAfter = ttl
+				default:
+					return fmt.Errorf("%w: field %s: unknown option %q", ErrInvalidIndex, field.Name, option)
+				}
+			}
+			key := bson.E{Key: path, Value: value}
+			if declared.Name != "" {
+				if position, ok := positions[declared.Name]; ok {
+					merged := &indexes[position]
+					merged.Keys = append(merged.Keys, key)
+					merged.Unique = merged.Unique || declared.Unique
+					merged.Sparse = merged.Sparse || declared.Sparse
+					merged.ExpireAfter = max(merged.ExpireAfter, declared.ExpireAfter)
+					continue
+				}
+				positions[declared.Name] = len(indexes)
+			}
+			declared.Keys = bson.D{key}
+			indexes = append(indexes, declared)
+		}
+		return nil
+	}
+	if t = structType(t); t == nil {
+		return nil, nil
+	}
+	if err := walk(t, ""); err != nil {
+		return nil, err
+	}
+	return indexes, nil
+}
+
+// indexName 与驱动一致的默认索引名称
+func indexName(keys bson.D) string {
+	parts := make([]string, 0, len(keys)*2)
+	for _, key := range keys {
+		parts = append(parts, key.Key, fmt.Sprint(key.Value))
+	}
+	return strings.Join(parts, "_")
+}
+
+func syncCollectionIndexes(ctx context.Context, coll *mongo.Collection, indexes []Index, prune bool) (IndexReport, error) {
+	report := IndexReport{Collection: coll.Name()}
+	existing, err := listIndexes(ctx, coll)
+	if err != nil {
+		return report, err
+	}
+	declared := make(map[string]bool, len(indexes))
+	for _, index := range indexes {
+		declared[index.Name] = true
+		current, ok := existing[index.Name]
+		if !ok {
+			if name, found := sameKeys(existing, index); found {
+				report.Different = append(report.Different, IndexDiff{Name: index.Name, Reason: fmt.Sprintf("same keys exist as index %q", name)})
+				declared[name] = true
+				continue
+			}
+			if _, err = coll.Indexes().CreateOne(ctx, index.model()); err != nil {
+				return report, err
+			}
+			report.Created = append(report.Created, index.Name)
+			continue
+		}
+		if reason := index.compare(current); reason != "" {
+			report.Different = append(report.Different, IndexDiff{Name: index.Name, Reason: reason})
+		}
+	}
+	names := make([]string, 0, len(existing))
+	for name := range existing {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if name == "_id_" || declared[name] {
+			continue
+		}
+		report.Undeclared = append(report.Undeclared, name)
+		if !prune {
+			continue
+		}
+		if err = coll.Indexes().DropOne(ctx, name); err != nil {
+			return report, err
+		}
+		report.Dropped = append(report.Dropped, name)
+	}
+	return report, nil
+}
+
+// indexSpec listIndexes 返回的索引描述
+type indexSpec struct {
+	Name                    string   `bson:"name"`
+	Key                     bson.D   `bson:"key"`
+	Unique                  bool     `bson:"unique"`
+	Sparse                  bool     `bson:"sparse"`
+	ExpireAfterSeconds      *int64   `bson:"expireAfterSeconds"`
+	Weights                 bson.M   `bson:"weights"`
+	PartialFilterExpression bson.Raw `bson:"partialFilterExpression"`
+	Collation               bson.Raw `bson:"collation"`
+}
+
+// listIndexes 按名称返回集合已有的索引 集合不存在时返回空
+func listIndexes(ctx context.Context, coll *mongo.Collection) (map[string]indexSpec, error) {
+	cursor, err := coll.Indexes().List(ctx)
+	if err != nil {
+		var serverErr mongo.ServerError
+		if errors.As(err, &serverErr) && serverErr.HasErrorCode(namespaceNotFoundCode) {
+			return map[string]indexSpec{}, nil
+		}
+		return nil, err
+	}
+	var specs []indexSpec
+	if err = cursor.All(ctx, &specs); err != nil {
+		return nil, err
+	}
+	result := make(map[string]indexSpec, len(specs))
+	for _, spec := range specs {
+		result[spec.Name] = spec
+	}
+	return result, nil
+}
+
+func sameKeys(existing map[string]indexSpec, index Index) (string, bool) {
+	for name, spec := range existing {
+		if index.compareKeys(spec) == "" {
+			return name, true
+		}
+	}
+	return "", false
+}
+
+func (i Index) model() mongo.IndexModel {
+	opts := options.Index().SetName(i.Name)
+	if i.Unique {
+		opts.SetUnique(true)
+	}
+	if i.Sparse {
+		opts.SetSparse(true)
+	}
+	if i.ExpireAfter > 0 {
+		opts.SetExpireAfterSeconds(int32(i.ExpireAfter / time.Second))
+	}
+	if i.PartialFilter != nil {
+		opts.SetPartialFilterExpression(i.PartialFilter)
+	}
+	if i.Collation != nil {
+		opts.SetCollation(i.Collation)
+	}
+	return mongo.IndexModel{Keys: i.Keys, Options: opts}
+}
+
+// compare 比较声明与已有索引 一致时返回空字符串
+func (i Index) compare(spec indexSpec) string {
+	if reason := i.compareKeys(spec); reason != "" {
+		return reason
+	}
+	if i.Unique != spec.Unique {
+		return fmt.Sprintf("unique: declared %t", i.Unique)
+	}
+	if i.Sparse != spec.Sparse {
+		return fmt.Sprintf("sparse: declared %t", i.Sparse)
+	}
+	declared, existing := int64(-1), int64(-1)
+	if i.ExpireAfter > 0 {
+		declared = int64(i.ExpireAfter / time.Second)
+	}
+	if spec.ExpireAfterSeconds != nil {
+		existing = *spec.ExpireAfterSeconds
+	}
+	if declared != existing {
+		return fmt.Sprintf("expireAfterSeconds: declared %d, existing %d", declared, existing)
+	}
+	if !documentEqual(i.PartialFilter, rawDocument(spec.PartialFilterExpression), false) {
+		return "partialFilterExpression differs"
+	}
+	// 服务端会补全排序规则的默认值 只比较声明的部分
+	if i.Collation != nil && !documentEqual(collationDocument(i.Collation), rawDocument(spec.Collation), true) {
+		return "collation differs"
+	}
+	if i.Collation == nil && spec.Collation != nil {
+		return "collation: declared none"
+	}
+	return ""
+}
+
+// compareKeys 按顺序比较索引键 文本索引的键保存为 _fts 与 _ftsx 文本字段记录在 weights 中
+func (i Index) compareKeys(spec indexSpec) string {
+	var declared, existing bson.D
+	textFields := make(map[string]bool)
+	for _, key := range i.Keys {
+		if key.Value == "text" {
+			textFields[key.Key] = true
+			continue
+		}
+		declared = append(declared, key)
+	}
+	for _, key := range spec.Key {
+		if key.Key != "_fts" && key.Key != "_ftsx" {
+			existing = append(existing, key)
+		}
+	}
+	if len(declared) != len(existing) {
+		return "keys differ"
+	}
+	for index := range declared {
+		if declared[index].Key != existing[index].Key ||
+			!documentEqual(bson.D{{Key: "v", Value: declared[index].Value}}, bson.D{{Key: "v", Value: existing[index].Value}}, false) {
+			return "keys differ"
+		}
+	}
+	if len(textFields) != len(spec.Weights) {
+		return "text fields differ"
+	}
+	for field := range spec.Weights {
+		if !textFields[field] {
+			return "text fields differ"
+		}
+	}
+	return ""
+}
+
+// collationDocument 按服务端字段名生成排序规则文档 options.Collation 的 bson 标签会将字段名转为小写
+func collationDocument(collation *options.Collation) bson.D {
+	document := bson.D{{Key: "locale", Value: collation.Locale}}
+	if collation.CaseLevel {
+		document = append(document, bson.E{Key: "caseLevel", Value: true})
+	}
+	if collation.CaseFirst != "" {
+		document = append(document, bson.E{Key: "caseFirst", Value: collation.CaseFirst})
+	}
+	if collation.Strength != 0 {
+		document = append(document, bson.E{Key: "strength", Value: collation.Strength})
+	}
+	if collation.NumericOrdering {
+		document = append(document, bson.E{Key: "numericOrdering", Value: true})
+	}
+	if collation.Alternate != "" {
+		document = append(document, bson.E{Key: "alternate", Value: collation.Alternate})
+	}
+	if collation.MaxVariable != "" {
+		document = append(document, bson.E{Key: "maxVariable", Value: collation.MaxVariable})
+	}
+	if collation.Normalization {
+		document = append(document, bson.E{Key: "normalization", Value: true})
+	}
+	if collation.Backwards {
+		document = append(document, bson.E{Key: "backwards", Value: true})
+	}
+	return document
+}
+
+func rawDocument(raw bson.Raw) any {
+	if raw == nil {
+		return nil
+	}
+	return raw
+}
+
+// documentEqual 以宽松扩展 JSON 比较两个文档 数值类型差异被忽略 subset 为 true 时只比较 declared 中的字段
+func documentEqual(declared, existing any, subset bool) bool {
+	if declared == nil || existing == nil {
+		return declared == nil && existing == nil
+	}
+	left, err := normalizeDocument(declared)
+	if err != nil {
+		return false
+	}
+	right, err := normalizeDocument(existing)
+	if err != nil {
+		return false
+	}
+	if !subset {
+		return reflect.DeepEqual(left, right)
+	}
+	for key, value := range left {
+		if !reflect.DeepEqual(value, right[key]) {
+			return false
+		}
+	}
+	return true
+}
+
+func normalizeDocument(document any) (map[string]any, error) {
+	data, err := bson.MarshalExtJSON(document, false, false)
+	if err != nil {
+		return nil, err
+	}
+	var result map[string]any
+	if err = json.Unmarshal(data, &result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
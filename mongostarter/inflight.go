@@ -0,0 +1,71 @@
+package mongostarter
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+// 当前客户端上进行中的 Mapper 操作 与 mongoClient 一同替换
+var mongoInflight *inflightTracker
+
+// inflightTracker 统计单个客户端上进行中的 Mapper 操作数量
+type inflightTracker struct {
+	count atomic.Int64
+}
+
+func newInflightTracker() *inflightTracker {
+	return &inflightTracker{}
+}
+
+func (t *inflightTracker) acquire() {
+	t.count.Add(1)
+}
+
+func (t *inflightTracker) release() {
+	t.count.Add(-1)
+}
+
+// running 进行中的操作数量
+func (t *inflightTracker) running() int64 {
+	return t.count.Load()
+}
+
+// wait 等待进行中的操作全部结束 ctx 结束时返回仍在进行的操作数量
+func (t *inflightTracker) wait(ctx context.Context) int64 {
+	ticker := time.NewTicker(10 * time.Millisecond)
+	defer ticker.Stop()
+	for {
+		running := t.running()
+		if running <= 0 {
+			return 0
+		}
+		select {
+		case <-ctx.Done():
+			return running
+		case <-ticker.C:
+		}
+	}
+}
+
+// acquireCollection 获取当前客户端上的集合并登记一个进行中的操作 操作结束后需调用 release
+// database 为空时使用默认数据库 name 按命名策略转换
+func acquireCollection(database, name string, opts ...options.Lister[options.CollectionOptions]) (coll *mongo.Collection, release func(), err error) {
+	mongoLock.RLock()
+	defer mongoLock.RUnlock()
+	if mongoClient == nil {
+		return nil, nil, ErrMongoStarterNotStarted
+	}
+	if mongoStopping {
+		return nil, nil, ErrMongoStarterStopping
+	}
+	if database == "" {
+		database = defaultDatabase
+	}
+	inflight := mongoInflight
+	inflight.acquire()
+	return mongoClient.Database(database).Collection(namingStrategy.apply(name), opts...), inflight.release, nil
+}
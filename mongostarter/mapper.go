@@ -10,14 +10,6 @@ import (
 	"go.mongodb.org/mongo-driver/v2/mongo/options"
 )
 
-func collection(name string) (*mongo.Collection, error) {
-	result := RawCollection(name)
-	if result == nil {
-		return nil, ErrMongoStarterNotStarted
-	}
-	return result, nil
-}
-
 func specifyColumnsOneOpt(specifyColumns ...string) *options.FindOneOptionsBuilder {
 	if len(specifyColumns) > 0 {
 		column := make(map[string]int, len(specifyColumns))
@@ -89,9 +81,68 @@ func isEmptyCondition(condition any) (bool, error) {
 	return len(document) == 0, nil
 }
 
-// Collection 获取当前 Mapper 对应的原始 Collection。
+// singleCount 将单条操作结果转换为文档数量
+func singleCount(err error) (int64, error) {
+	if err != nil {
+		return 0, err
+	}
+	return 1, nil
+}
+
+// multipleCount 将多条查询结果转换为文档数量 查询失败或 result 为 nil 时不读取结果
+func multipleCount[R any](result *[]*R, err error) (int64, error) {
+	if err != nil || result == nil {
+		return 0, err
+	}
+	return int64(len(*result)), nil
+}
+
+// WithContext 返回绑定指定 context 的 Mapper 副本，后续操作使用该 context 执行
+func (b BaseMapper[T]) WithContext(ctx context.Context) BaseMapper[T] {
+	b.ctx = ctx
+	return b
+}
+
+// operationContext 获取当前 Mapper 绑定的 context，未绑定时使用 context.Background()
+func (b BaseMapper[T]) operationContext() context.Context {
+	if b.ctx != nil {
+		return b.ctx
+	}
+	return context.Background()
+}
+
+// execute 解析集合并执行 Mapper 操作，fn 返回本次操作涉及的文档数量
+func (b BaseMapper[T]) execute(operation string, fn func(ctx context.Context, coll *mongo.Collection) (int64, error)) error {
+	return b.executeIn("", operation, fn)
+}
+
+// executeIn 在指定集合上执行 Mapper 操作，collection 为空时按 Mapper 规则解析集合名称
+func (b BaseMapper[T]) executeIn(collection, operation string, fn func(ctx context.Context, coll *mongo.Collection) (int64, error)) error {
+	ctx := b.operationContext()
+	if collection == "" {
+		collection = b.collectionName(ctx, nil)
+	}
+	coll, release, err := acquireCollection(b.databaseName(), collection, b.readWriteOptions(ctx).collectionOptions()...)
+	if err != nil {
+		return err
+	}
+	defer release()
+	ctx, span := startMapperSpan(ctx, operation, coll)
+	count, err := fn(ctx, coll)
+	endMapperSpan(span, count, err)
+	if err != nil && isAuthError(err) {
+		notifyAuthFailure()
+	}
+	return err
+}
+
+// Collection 获取当前 Mapper 对应的原始 Collection，按 Mapper 规则解析数据库与集合名称，并应用模型、Mapper 以及绑定 context 中的读写选项。
 func (b BaseMapper[T]) Collection() *mongo.Collection {
-	return RawCollection(b.model.CollectionName())
+	db := RawDatabase(b.databaseName())
+	if db == nil {
+		return nil
+	}
+	return db.Collection(ApplyNaming(b.collectionName(b.operationContext(), nil)), b.readWriteOptions(b.ctx).collectionOptions()...)
 }
 
 // SelectByID 通过主键查询数据，默认将字符串 ID 转换为 ObjectID；普通字符串 ID 需要将 notObjectID 设置为 true
@@ -100,9 +151,9 @@ func (b BaseMapper[T]) SelectByID(id any, result *T, notObjectID ...bool) error
 	if err != nil {
 		return err
 	}
-	coll, err := collection(b.model.CollectionName())
-	if err != nil { return err }
-	return checkSingleResult(coll.FindOne(context.Background(), bson.M{"_id": queryID}), result)
+	return b.execute("SelectByID", func(ctx context.Context, coll *mongo.Collection) (int64, error) {
+		return singleCount(checkSingleResult(coll.FindOne(ctx, bson.M{"_id": queryID}), result))
+	})
 }
 
 // SelectByIDs 通过多个主键查询数据，默认将字符串 ID 转换为 ObjectID；普通字符串 ID 需要将 notObjectID 设置为 true
@@ -118,10 +169,11 @@ func (b BaseMapper[T]) SelectByIDs(ids []any, result *[]*T, notObjectID ...bool)
 		}
 		queryIDs = append(queryIDs, queryID)
 	}
-	coll, err := collection(b.model.CollectionName())
-	if err != nil { return err }
-	cursor, err := coll.Find(context.Background(), bson.M{"_id": bson.M{"$in": queryIDs}})
-	return checkMultipleResult(cursor, err, result)
+	return b.execute("SelectByIDs", func(ctx context.Context, coll *mongo.Collection) (int64, error) {
+		cursor, err := coll.Find(ctx, bson.M{"_id": bson.M{"$in": queryIDs}})
+		err = checkMultipleResult(ctx, cursor, err, result)
+		return multipleCount(result, err)
+	})
 }
 
 // ExistsByID 判断指定主键的数据是否存在
@@ -130,11 +182,11 @@ func (b BaseMapper[T]) ExistsByID(id any, notObjectID ...bool) (bool, error) {
 	if err != nil {
 		return false, err
 	}
-	coll, err := collection(b.model.CollectionName())
-	if err != nil {
-		return false, err
-	}
-	count, err := coll.CountDocuments(context.Background(), bson.M{"_id": queryID})
+	var count int64
+	err = b.execute("ExistsByID", func(ctx context.Context, coll *mongo.Collection) (int64, error) {
+		count, err = coll.CountDocuments(ctx, bson.M{"_id": queryID})
+		return count, err
+	})
 	if err != nil {
 		return false, err
 	}
@@ -144,37 +196,40 @@ func (b BaseMapper[T]) ExistsByID(id any, notObjectID ...bool) (bool, error) {
 // SelectOneByCond 通过条件查询
 // specifyColumns 需要指定只查询的数据库字段
 func (b BaseMapper[T]) SelectOneByCond(condition *T, result *T, specifyColumns ...string) error {
-	coll, err := collection(b.model.CollectionName())
-	if err != nil { return err }
-	return checkSingleResult(coll.FindOne(context.Background(), condition, specifyColumnsOneOpt(specifyColumns...)), result)
+	filter := b.condition(condition)
+	return b.execute("SelectOneByCond", func(ctx context.Context, coll *mongo.Collection) (int64, error) {
+		return singleCount(checkSingleResult(coll.FindOne(ctx, filter, specifyColumnsOneOpt(specifyColumns...)), result))
+	})
 }
 
 // SelectOneByBSON 通过 BSON 条件查询一条数据
 // specifyColumns 需要指定只查询的数据库字段
 func (b BaseMapper[T]) SelectOneByBSON(condition bson.M, result *T, specifyColumns ...string) error {
-	coll, err := collection(b.model.CollectionName())
-	if err != nil { return err }
-	return checkSingleResult(coll.FindOne(context.Background(), condition, specifyColumnsOneOpt(specifyColumns...)), result)
+	return b.execute("SelectOneByBSON", func(ctx context.Context, coll *mongo.Collection) (int64, error) {
+		return singleCount(checkSingleResult(coll.FindOne(ctx, condition, specifyColumnsOneOpt(specifyColumns...)), result))
+	})
 }
 
 // SelectOneWithOptions 使用原生 FindOneOptions 查询一条数据
 func (b BaseMapper[T]) SelectOneWithOptions(filter any, result *T, opts ...options.Lister[options.FindOneOptions]) error {
-	coll, err := collection(b.model.CollectionName())
-	if err != nil { return err }
-	return checkSingleResult(coll.FindOne(context.Background(), filter, opts...), result)
+	return b.execute("SelectOneWithOptions", func(ctx context.Context, coll *mongo.Collection) (int64, error) {
+		return singleCount(checkSingleResult(coll.FindOne(ctx, filter, opts...), result))
+	})
 }
 
 // SelectByCond 通过条件查询
 // specifyColumns 需要指定只查询的数据库字段
 func (b BaseMapper[T]) SelectByCond(condition *T, orderBy []*OrderBy, result *[]*T, specifyColumns ...string) error {
+	filter := b.condition(condition)
 	opt := specifyColumnsOpt(specifyColumns...)
 	if len(orderBy) > 0 {
 		setOrderBy(&opt, orderBy)
 	}
-	coll, err := collection(b.model.CollectionName())
-	if err != nil { return err }
-	cursor, err := coll.Find(context.Background(), condition, opt)
-	return checkMultipleResult(cursor, err, result)
+	return b.execute("SelectByCond", func(ctx context.Context, coll *mongo.Collection) (int64, error) {
+		cursor, err := coll.Find(ctx, filter, opt)
+		err = checkMultipleResult(ctx, cursor, err, result)
+		return multipleCount(result, err)
+	})
 }
 
 // SelectByBSON 通过 BSON 条件查询数据
@@ -184,88 +239,99 @@ func (b BaseMapper[T]) SelectByBSON(condition bson.M, orderBy []*OrderBy, result
 	if len(orderBy) > 0 {
 		setOrderBy(&opt, orderBy)
 	}
-	coll, err := collection(b.model.CollectionName())
-	if err != nil { return err }
-	cursor, err := coll.Find(context.Background(), condition, opt)
-	return checkMultipleResult(cursor, err, result)
+	return b.execute("SelectByBSON", func(ctx context.Context, coll *mongo.Collection) (int64, error) {
+		cursor, err := coll.Find(ctx, condition, opt)
+		err = checkMultipleResult(ctx, cursor, err, result)
+		return multipleCount(result, err)
+	})
 }
 
 // SelectWithOptions 使用原生 FindOptions 查询数据
 func (b BaseMapper[T]) SelectWithOptions(filter any, result *[]*T, opts ...options.Lister[options.FindOptions]) error {
-	coll, err := collection(b.model.CollectionName())
-	if err != nil { return err }
-	cursor, err := coll.Find(context.Background(), filter, opts...)
-	return checkMultipleResult(cursor, err, result)
+	return b.execute("SelectWithOptions", func(ctx context.Context, coll *mongo.Collection) (int64, error) {
+		cursor, err := coll.Find(ctx, filter, opts...)
+		err = checkMultipleResult(ctx, cursor, err, result)
+		return multipleCount(result, err)
+	})
 }
 
 // CountByCond 通过条件查询数据总数
-func (b BaseMapper[T]) CountByCond(condition *T) (int64, error) {
-	coll, err := collection(b.model.CollectionName())
-	if err != nil { return 0, err }
-	return coll.CountDocuments(context.Background(), condition)
+func (b BaseMapper[T]) CountByCond(condition *T) (total int64, err error) {
+	filter := b.condition(condition)
+	err = b.execute("CountByCond", func(ctx context.Context, coll *mongo.Collection) (int64, error) {
+		total, err = coll.CountDocuments(ctx, filter)
+		return total, err
+	})
+	return total, err
 }
 
 // CountByBSON 通过 BSON 条件统计数据总数
-func (b BaseMapper[T]) CountByBSON(condition bson.M) (int64, error) {
-	coll, err := collection(b.model.CollectionName())
-	if err != nil { return 0, err }
-	return coll.CountDocuments(context.Background(), condition)
+func (b BaseMapper[T]) CountByBSON(condition bson.M) (total int64, err error) {
+	err = b.execute("CountByBSON", func(ctx context.Context, coll *mongo.Collection) (int64, error) {
+		total, err = coll.CountDocuments(ctx, condition)
+		return total, err
+	})
+	return total, err
 }
 
 // CountWithOptions 使用原生 CountOptions 统计数据总数
-func (b BaseMapper[T]) CountWithOptions(filter any, opts ...options.Lister[options.CountOptions]) (int64, error) {
-	coll, err := collection(b.model.CollectionName())
-	if err != nil { return 0, err }
-	return coll.CountDocuments(context.Background(), filter, opts...)
+func (b BaseMapper[T]) CountWithOptions(filter any, opts ...options.Lister[options.CountOptions]) (total int64, err error) {
+	err = b.execute("CountWithOptions", func(ctx context.Context, coll *mongo.Collection) (int64, error) {
+		total, err = coll.CountDocuments(ctx, filter, opts...)
+		return total, err
+	})
+	return total, err
 }
 
 // SelectPageByCond 通过实体条件分页查询
 func (b BaseMapper[T]) SelectPageByCond(condition *T, query PageQuery, result *[]*T) (total int64, err error) {
-	if query.PageNumber <= 0 || query.PageSize <= 0 {
-		return 0, ErrInvalidPage
-	}
-	total, err = b.CountByCond(condition)
-	if err != nil {
-		return 0, err
-	}
-	opt := specifyColumnsOpt(query.SpecifyColumns...)
-	if len(query.OrderBy) > 0 {
-		setOrderBy(&opt, query.OrderBy)
-	}
-	setPage(&opt, query.PageNumber, query.PageSize)
-	coll, err := collection(b.model.CollectionName())
-	if err != nil { return 0, err }
-	cursor, err := coll.Find(context.Background(), condition, opt)
-	return total, checkMultipleResult(cursor, err, result)
+	count, err := b.selectPage("SelectPageByCond", b.condition(condition), query, result)
+	return count.Total, err
 }
 
 // SelectPageByBSON 通过 BSON 条件分页查询
 func (b BaseMapper[T]) SelectPageByBSON(condition bson.M, query PageQuery, result *[]*T) (total int64, err error) {
-	if query.PageNumber <= 0 || query.PageSize <= 0 {
-		return 0, ErrInvalidPage
+	count, err := b.selectPage("SelectPageByBSON", condition, query, result)
+	return count.Total, err
+}
+
+// SelectPage 分页查询并返回总数统计信息 包含实际使用的统计方式以及是否达到上限或来自缓存
+// condition 可以是 *T 实体条件、bson.M 或其他过滤文档 nil 表示查询全部
+func (b BaseMapper[T]) SelectPage(condition any, query PageQuery, result *[]*T) (PageCount, error) {
+	return b.selectPage("SelectPage", b.filter(condition), query, result)
+}
+
+// selectPage 按 PageQuery 的投影、排序与统计方式分页查询
+func (b BaseMapper[T]) selectPage(operation string, filter any, query PageQuery, result *[]*T) (count PageCount, err error) {
+	if err = query.validate(); err != nil {
+		return PageCount{}, err
 	}
-	total, err = b.CountByBSON(condition)
+	opt, err := pageProjection(query)
 	if err != nil {
-		return 0, err
+		return PageCount{}, err
 	}
-	opt := specifyColumnsOpt(query.SpecifyColumns...)
 	if len(query.OrderBy) > 0 {
 		setOrderBy(&opt, query.OrderBy)
 	}
 	setPage(&opt, query.PageNumber, query.PageSize)
-	coll, err := collection(b.model.CollectionName())
-	if err != nil { return 0, err }
-	cursor, err := coll.Find(context.Background(), condition, opt)
-	return total, checkMultipleResult(cursor, err, result)
+	err = b.execute(operation, func(ctx context.Context, coll *mongo.Collection) (int64, error) {
+		count, err = countPage(ctx, coll, filter, query)
+		if err != nil {
+			return 0, err
+		}
+		cursor, err := coll.Find(ctx, filter, opt)
+		err = checkMultipleResult(ctx, cursor, err, result)
+		return multipleCount(result, err)
+	})
+	if err != nil {
+		return PageCount{}, err
+	}
+	return count, nil
 }
 
 // SelectPageWithOptions 使用原生查询选项分页查询
 func (b BaseMapper[T]) SelectPageWithOptions(filter any, query PageQuery, result *[]*T) (total int64, err error) {
-	if query.PageNumber <= 0 || query.PageSize <= 0 {
-		return 0, ErrInvalidPage
-	}
-	total, err = b.CountWithOptions(filter, query.CountOptions...)
-	if err != nil {
+	if err = query.validate(); err != nil {
 		return 0, err
 	}
 
@@ -282,52 +348,97 @@ func (b BaseMapper[T]) SelectPageWithOptions(filter any, query PageQuery, result
 	}
 	skip := (query.PageNumber - 1) * query.PageSize
 	query.FindOptions = append(query.FindOptions, options.Find().SetSkip(int64(skip)).SetLimit(int64(query.PageSize)))
-	coll, err := collection(b.model.CollectionName())
-	if err != nil { return 0, err }
-	cursor, err := coll.Find(context.Background(), filter, query.FindOptions...)
-	return total, checkMultipleResult(cursor, err, result)
+	err = b.execute("SelectPageWithOptions", func(ctx context.Context, coll *mongo.Collection) (int64, error) {
+		count, err := countPage(ctx, coll, filter, query, query.CountOptions...)
+		if err != nil {
+			return 0, err
+		}
+		total = count.Total
+		cursor, err := coll.Find(ctx, filter, query.FindOptions...)
+		err = checkMultipleResult(ctx, cursor, err, result)
+		return multipleCount(result, err)
+	})
+	if err != nil {
+		return 0, err
+	}
+	return total, nil
 }
 
-// Insert 保存数据
-func (b BaseMapper[T]) Insert(entity *T) (string, error) {
-	coll, err := collection(b.model.CollectionName())
-	if err != nil { return "", err }
-	return checkSingleInsertResult(coll.InsertOne(context.Background(), entity))
+// Insert 保存数据，模型实现 CollectionResolver 时按实体解析集合名称
+func (b BaseMapper[T]) Insert(entity *T) (id string, err error) {
+	err = b.executeIn(b.collectionName(b.operationContext(), entity), "Insert", func(ctx context.Context, coll *mongo.Collection) (int64, error) {
+		id, err = checkSingleInsertResult(coll.InsertOne(ctx, entity))
+		return singleCount(err)
+	})
+	return id, err
 }
 
 // InsertWithBSON 使用 BSON 文档插入数据
-func (b BaseMapper[T]) InsertWithBSON(entity bson.M) (string, error) {
-	coll, err := collection(b.model.CollectionName())
-	if err != nil { return "", err }
-	return checkSingleInsertResult(coll.InsertOne(context.Background(), entity))
+func (b BaseMapper[T]) InsertWithBSON(entity bson.M) (id string, err error) {
+	err = b.execute("InsertWithBSON", func(ctx context.Context, coll *mongo.Collection) (int64, error) {
+		id, err = checkSingleInsertResult(coll.InsertOne(ctx, entity))
+		return singleCount(err)
+	})
+	return id, err
 }
 
 // InsertWithOptions 使用原生 InsertOneOptions 插入数据
-func (b BaseMapper[T]) InsertWithOptions(document any, opts ...options.Lister[options.InsertOneOptions]) (string, error) {
-	coll, err := collection(b.model.CollectionName())
-	if err != nil { return "", err }
-	return checkSingleInsertResult(coll.InsertOne(context.Background(), document, opts...))
-}
-
-// InsertBatch 批量保存数据
-func (b BaseMapper[T]) InsertBatch(entities []*T) ([]string, error) {
-	coll, err := collection(b.model.CollectionName())
-	if err != nil { return nil, err }
-	return checkMultipleInsertResult(coll.InsertMany(context.Background(), entities))
+func (b BaseMapper[T]) InsertWithOptions(document any, opts ...options.Lister[options.InsertOneOptions]) (id string, err error) {
+	err = b.execute("InsertWithOptions", func(ctx context.Context, coll *mongo.Collection) (int64, error) {
+		id, err = checkSingleInsertResult(coll.InsertOne(ctx, document, opts...))
+		return singleCount(err)
+	})
+	return id, err
+}
+
+// InsertBatch 批量保存数据，模型实现 CollectionResolver 时按实体解析集合名称
+// 实体分属多个集合时按集合分组依次插入，失败时已插入的分组不会回滚
+func (b BaseMapper[T]) InsertBatch(entities []*T) (ids []string, err error) {
+	groups := b.groupByCollection(entities)
+	if len(groups) <= 1 {
+		var collection string
+		if len(groups) == 1 {
+			collection = groups[0].collection
+		}
+		err = b.executeIn(collection, "InsertBatch", func(ctx context.Context, coll *mongo.Collection) (int64, error) {
+			ids, err = checkMultipleInsertResult(coll.InsertMany(ctx, entities))
+			return int64(len(ids)), err
+		})
+		return ids, err
+	}
+	ids = make([]string, len(entities))
+	for _, group := range groups {
+		var inserted []string
+		err = b.executeIn(group.collection, "InsertBatch", func(ctx context.Context, coll *mongo.Collection) (int64, error) {
+			inserted, err = checkMultipleInsertResult(coll.InsertMany(ctx, group.entities))
+			return int64(len(inserted)), err
+		})
+		if err != nil {
+			return nil, err
+		}
+		for i, index := range group.indexes {
+			ids[index] = inserted[i]
+		}
+	}
+	return ids, nil
 }
 
 // InsertBatchWithBSON 使用 BSON 文档批量插入数据
-func (b BaseMapper[T]) InsertBatchWithBSON(entities bson.A) ([]string, error) {
-	coll, err := collection(b.model.CollectionName())
-	if err != nil { return nil, err }
-	return checkMultipleInsertResult(coll.InsertMany(context.Background(), entities))
+func (b BaseMapper[T]) InsertBatchWithBSON(entities bson.A) (ids []string, err error) {
+	err = b.execute("InsertBatchWithBSON", func(ctx context.Context, coll *mongo.Collection) (int64, error) {
+		ids, err = checkMultipleInsertResult(coll.InsertMany(ctx, entities))
+		return int64(len(ids)), err
+	})
+	return ids, err
 }
 
 // InsertBatchWithOptions 使用原生 InsertManyOptions 批量插入数据
-func (b BaseMapper[T]) InsertBatchWithOptions(documents any, opts ...options.Lister[options.InsertManyOptions]) ([]string, error) {
-	coll, err := collection(b.model.CollectionName())
-	if err != nil { return nil, err }
-	return checkMultipleInsertResult(coll.InsertMany(context.Background(), documents, opts...))
+func (b BaseMapper[T]) InsertBatchWithOptions(documents any, opts ...options.Lister[options.InsertManyOptions]) (ids []string, err error) {
+	err = b.execute("InsertBatchWithOptions", func(ctx context.Context, coll *mongo.Collection) (int64, error) {
+		ids, err = checkMultipleInsertResult(coll.InsertMany(ctx, documents, opts...))
+		return int64(len(ids)), err
+	})
+	return ids, err
 }
 
 func (b BaseMapper[T]) convertID(id any, notObjectID ...bool) (any, error) {
@@ -350,77 +461,103 @@ func (b BaseMapper[T]) convertID(id any, notObjectID ...bool) (any, error) {
 }
 
 // UpdateByID 根据主键更新数据
-func (b BaseMapper[T]) UpdateByID(update *T, id any, notObjectID ...bool) (int64, error) {
+func (b BaseMapper[T]) UpdateByID(update *T, id any, notObjectID ...bool) (modified int64, err error) {
 	queryID, err := b.convertID(id, notObjectID...)
 	if err != nil {
 		return 0, err
 	}
-	coll, err := collection(b.model.CollectionName())
-	if err != nil { return 0, err }
-	return checkUpdateResult(coll.UpdateByID(context.Background(), queryID, bson.M{"$set": update}))
+	document, err := b.update(update)
+	if err != nil {
+		return 0, err
+	}
+	err = b.execute("UpdateByID", func(ctx context.Context, coll *mongo.Collection) (int64, error) {
+		modified, err = checkUpdateResult(coll.UpdateByID(ctx, queryID, document))
+		return modified, err
+	})
+	return modified, err
 }
 
 // UpdateByIDWithBSON 根据主键使用 BSON 文档更新数据
-func (b BaseMapper[T]) UpdateByIDWithBSON(update bson.M, id any, notObjectID ...bool) (int64, error) {
+func (b BaseMapper[T]) UpdateByIDWithBSON(update bson.M, id any, notObjectID ...bool) (modified int64, err error) {
 	queryID, err := b.convertID(id, notObjectID...)
 	if err != nil {
 		return 0, err
 	}
-	coll, err := collection(b.model.CollectionName())
-	if err != nil { return 0, err }
-	return checkUpdateResult(coll.UpdateByID(context.Background(), queryID, bson.M{"$set": update}))
+	err = b.execute("UpdateByIDWithBSON", func(ctx context.Context, coll *mongo.Collection) (int64, error) {
+		modified, err = checkUpdateResult(coll.UpdateByID(ctx, queryID, bson.M{"$set": update}))
+		return modified, err
+	})
+	return modified, err
 }
 
 // UpdateOneByCond 通过条件更新单条数据
-func (b BaseMapper[T]) UpdateOneByCond(update, condition *T) (int64, error) {
-	empty, err := isEmptyCondition(condition)
+func (b BaseMapper[T]) UpdateOneByCond(update, condition *T) (modified int64, err error) {
+	filter := b.condition(condition)
+	empty, err := isEmptyCondition(filter)
 	if err != nil {
 		return 0, err
 	}
 	if empty {
 		return 0, ErrEmptyCondition
 	}
-	coll, err := collection(b.model.CollectionName())
-	if err != nil { return 0, err }
-	return checkUpdateResult(coll.UpdateOne(context.Background(), condition, bson.M{"$set": update}))
+	document, err := b.update(update)
+	if err != nil {
+		return 0, err
+	}
+	err = b.execute("UpdateOneByCond", func(ctx context.Context, coll *mongo.Collection) (int64, error) {
+		modified, err = checkUpdateResult(coll.UpdateOne(ctx, filter, document))
+		return modified, err
+	})
+	return modified, err
 }
 
 // UpdateOneByBSON 通过 BSON 条件更新一条数据
-func (b BaseMapper[T]) UpdateOneByBSON(update, condition bson.M) (int64, error) {
+func (b BaseMapper[T]) UpdateOneByBSON(update, condition bson.M) (modified int64, err error) {
 	if len(condition) == 0 {
 		return 0, ErrEmptyCondition
 	}
-	coll, err := collection(b.model.CollectionName())
-	if err != nil { return 0, err }
-	return checkUpdateResult(coll.UpdateOne(context.Background(), condition, bson.M{"$set": update}))
+	err = b.execute("UpdateOneByBSON", func(ctx context.Context, coll *mongo.Collection) (int64, error) {
+		modified, err = checkUpdateResult(coll.UpdateOne(ctx, condition, bson.M{"$set": update}))
+		return modified, err
+	})
+	return modified, err
 }
 
 // UpdateByCond 通过条件更新多条数据
-func (b BaseMapper[T]) UpdateByCond(update, condition *T) (int64, error) {
-	empty, err := isEmptyCondition(condition)
+func (b BaseMapper[T]) UpdateByCond(update, condition *T) (modified int64, err error) {
+	filter := b.condition(condition)
+	empty, err := isEmptyCondition(filter)
 	if err != nil {
 		return 0, err
 	}
 	if empty {
 		return 0, ErrEmptyCondition
 	}
-	coll, err := collection(b.model.CollectionName())
-	if err != nil { return 0, err }
-	return checkUpdateResult(coll.UpdateMany(context.Background(), condition, bson.M{"$set": update}))
+	document, err := b.update(update)
+	if err != nil {
+		return 0, err
+	}
+	err = b.execute("UpdateByCond", func(ctx context.Context, coll *mongo.Collection) (int64, error) {
+		modified, err = checkUpdateResult(coll.UpdateMany(ctx, filter, document))
+		return modified, err
+	})
+	return modified, err
 }
 
 // UpdateByBSON 通过 BSON 条件更新多条数据
-func (b BaseMapper[T]) UpdateByBSON(update, condition bson.M) (int64, error) {
+func (b BaseMapper[T]) UpdateByBSON(update, condition bson.M) (modified int64, err error) {
 	if len(condition) == 0 {
 		return 0, ErrEmptyCondition
 	}
-	coll, err := collection(b.model.CollectionName())
-	if err != nil { return 0, err }
-	return checkUpdateResult(coll.UpdateMany(context.Background(), condition, bson.M{"$set": update}))
+	err = b.execute("UpdateByBSON", func(ctx context.Context, coll *mongo.Collection) (int64, error) {
+		modified, err = checkUpdateResult(coll.UpdateMany(ctx, condition, bson.M{"$set": update}))
+		return modified, err
+	})
+	return modified, err
 }
 
 // UpdateOneWithOptions 使用原生 UpdateOneOptions 更新单条数据
-func (b BaseMapper[T]) UpdateOneWithOptions(filter, update any, opts ...options.Lister[options.UpdateOneOptions]) (int64, error) {
+func (b BaseMapper[T]) UpdateOneWithOptions(filter, update any, opts ...options.Lister[options.UpdateOneOptions]) (modified int64, err error) {
 	empty, err := isEmptyCondition(filter)
 	if err != nil {
 		return 0, err
@@ -428,15 +565,15 @@ func (b BaseMapper[T]) UpdateOneWithOptions(filter, update any, opts ...options.
 	if empty {
 		return 0, ErrEmptyCondition
 	}
-	coll, err := collection(b.model.CollectionName())
-	if err != nil {
-		return 0, err
-	}
-	return checkUpdateResult(coll.UpdateOne(context.Background(), filter, update, opts...))
+	err = b.execute("UpdateOneWithOptions", func(ctx context.Context, coll *mongo.Collection) (int64, error) {
+		modified, err = checkUpdateResult(coll.UpdateOne(ctx, filter, update, opts...))
+		return modified, err
+	})
+	return modified, err
 }
 
 // UpdateWithOptions 使用原生 UpdateManyOptions 更新多条数据
-func (b BaseMapper[T]) UpdateWithOptions(filter, update any, opts ...options.Lister[options.UpdateManyOptions]) (int64, error) {
+func (b BaseMapper[T]) UpdateWithOptions(filter, update any, opts ...options.Lister[options.UpdateManyOptions]) (modified int64, err error) {
 	empty, err := isEmptyCondition(filter)
 	if err != nil {
 		return 0, err
@@ -444,26 +581,28 @@ func (b BaseMapper[T]) UpdateWithOptions(filter, update any, opts ...options.Lis
 	if empty {
 		return 0, ErrEmptyCondition
 	}
-	coll, err := collection(b.model.CollectionName())
-	if err != nil {
-		return 0, err
-	}
-	return checkUpdateResult(coll.UpdateMany(context.Background(), filter, update, opts...))
+	err = b.execute("UpdateWithOptions", func(ctx context.Context, coll *mongo.Collection) (int64, error) {
+		modified, err = checkUpdateResult(coll.UpdateMany(ctx, filter, update, opts...))
+		return modified, err
+	})
+	return modified, err
 }
 
 // DeleteByID 根据主键删除数据
-func (b BaseMapper[T]) DeleteByID(id any, notObjectID ...bool) (int64, error) {
+func (b BaseMapper[T]) DeleteByID(id any, notObjectID ...bool) (deleted int64, err error) {
 	queryID, err := b.convertID(id, notObjectID...)
 	if err != nil {
 		return 0, err
 	}
-	coll, err := collection(b.model.CollectionName())
-	if err != nil { return 0, err }
-	return checkDeleteResult(coll.DeleteOne(context.Background(), bson.M{"_id": queryID}))
+	err = b.execute("DeleteByID", func(ctx context.Context, coll *mongo.Collection) (int64, error) {
+		deleted, err = checkDeleteResult(coll.DeleteOne(ctx, bson.M{"_id": queryID}))
+		return deleted, err
+	})
+	return deleted, err
 }
 
 // DeleteByIDs 根据多个主键删除数据
-func (b BaseMapper[T]) DeleteByIDs(ids []any, notObjectID ...bool) (int64, error) {
+func (b BaseMapper[T]) DeleteByIDs(ids []any, notObjectID ...bool) (deleted int64, err error) {
 	if len(ids) == 0 {
 		return 0, ErrEmptyIDs
 	}
@@ -475,63 +614,73 @@ func (b BaseMapper[T]) DeleteByIDs(ids []any, notObjectID ...bool) (int64, error
 		}
 		queryIDs = append(queryIDs, queryID)
 	}
-	coll, err := collection(b.model.CollectionName())
-	if err != nil {
-		return 0, err
-	}
-	return checkDeleteResult(coll.DeleteMany(context.Background(), bson.M{"_id": bson.M{"$in": queryIDs}}))
+	err = b.execute("DeleteByIDs", func(ctx context.Context, coll *mongo.Collection) (int64, error) {
+		deleted, err = checkDeleteResult(coll.DeleteMany(ctx, bson.M{"_id": bson.M{"$in": queryIDs}}))
+		return deleted, err
+	})
+	return deleted, err
 }
 
 // DeleteOneByCond 通过条件删除数据
-func (b BaseMapper[T]) DeleteOneByCond(condition *T) (int64, error) {
-	empty, err := isEmptyCondition(condition)
+func (b BaseMapper[T]) DeleteOneByCond(condition *T) (deleted int64, err error) {
+	filter := b.condition(condition)
+	empty, err := isEmptyCondition(filter)
 	if err != nil {
 		return 0, err
 	}
 	if empty {
 		return 0, ErrEmptyCondition
 	}
-	coll, err := collection(b.model.CollectionName())
-	if err != nil { return 0, err }
-	return checkDeleteResult(coll.DeleteOne(context.Background(), condition))
+	err = b.execute("DeleteOneByCond", func(ctx context.Context, coll *mongo.Collection) (int64, error) {
+		deleted, err = checkDeleteResult(coll.DeleteOne(ctx, filter))
+		return deleted, err
+	})
+	return deleted, err
 }
 
 // DeleteOneByBSON 通过 BSON 条件删除一条数据
-func (b BaseMapper[T]) DeleteOneByBSON(condition bson.M) (int64, error) {
+func (b BaseMapper[T]) DeleteOneByBSON(condition bson.M) (deleted int64, err error) {
 	if len(condition) == 0 {
 		return 0, ErrEmptyCondition
 	}
-	coll, err := collection(b.model.CollectionName())
-	if err != nil { return 0, err }
-	return checkDeleteResult(coll.DeleteOne(context.Background(), condition))
+	err = b.execute("DeleteOneByBSON", func(ctx context.Context, coll *mongo.Collection) (int64, error) {
+		deleted, err = checkDeleteResult(coll.DeleteOne(ctx, condition))
+		return deleted, err
+	})
+	return deleted, err
 }
 
 // DeleteByCond 通过条件删除数据
-func (b BaseMapper[T]) DeleteByCond(condition *T) (int64, error) {
-	empty, err := isEmptyCondition(condition)
+func (b BaseMapper[T]) DeleteByCond(condition *T) (deleted int64, err error) {
+	filter := b.condition(condition)
+	empty, err := isEmptyCondition(filter)
 	if err != nil {
 		return 0, err
 	}
 	if empty {
 		return 0, ErrEmptyCondition
 	}
-	coll, err := collection(b.model.CollectionName())
-	if err != nil { return 0, err }
-	return checkDeleteResult(coll.DeleteMany(context.Background(), condition))
+	err = b.execute("DeleteByCond", func(ctx context.Context, coll *mongo.Collection) (int64, error) {
+		deleted, err = checkDeleteResult(coll.DeleteMany(ctx, filter))
+		return deleted, err
+	})
+	return deleted, err
 }
 
 // DeleteByBSON 通过 BSON 条件删除多条数据
-func (b BaseMapper[T]) DeleteByBSON(condition bson.M) (int64, error) {
+func (b BaseMapper[T]) DeleteByBSON(condition bson.M) (deleted int64, err error) {
 	if len(condition) == 0 {
 		return 0, ErrEmptyCondition
 	}
-	coll, err := collection(b.model.CollectionName())
-	if err != nil { return 0, err }
-	return checkDeleteResult(coll.DeleteMany(context.Background(), condition))
+	err = b.execute("DeleteByBSON", func(ctx context.Context, coll *mongo.Collection) (int64, error) {
+		deleted, err = checkDeleteResult(coll.DeleteMany(ctx, condition))
+		return deleted, err
+	})
+	return deleted, err
 }
 
 // DeleteOneWithOptions 使用原生 DeleteOneOptions 删除单条数据
-func (b BaseMapper[T]) DeleteOneWithOptions(filter any, opts ...options.Lister[options.DeleteOneOptions]) (int64, error) {
+func (b BaseMapper[T]) DeleteOneWithOptions(filter any, opts ...options.Lister[options.DeleteOneOptions]) (deleted int64, err error) {
 	empty, err := isEmptyCondition(filter)
 	if err != nil {
 		return 0, err
@@ -539,15 +688,15 @@ func (b BaseMapper[T]) DeleteOneWithOptions(filter any, opts ...options.Lister[o
 	if empty {
 		return 0, ErrEmptyCondition
 	}
-	coll, err := collection(b.model.CollectionName())
-	if err != nil {
-		return 0, err
-	}
-	return checkDeleteResult(coll.DeleteOne(context.Background(), filter, opts...))
+	err = b.execute("DeleteOneWithOptions", func(ctx context.Context, coll *mongo.Collection) (int64, error) {
+		deleted, err = checkDeleteResult(coll.DeleteOne(ctx, filter, opts...))
+		return deleted, err
+	})
+	return deleted, err
 }
 
 // DeleteWithOptions 使用原生 DeleteManyOptions 删除多条数据
-func (b BaseMapper[T]) DeleteWithOptions(filter any, opts ...options.Lister[options.DeleteManyOptions]) (int64, error) {
+func (b BaseMapper[T]) DeleteWithOptions(filter any, opts ...options.Lister[options.DeleteManyOptions]) (deleted int64, err error) {
 	empty, err := isEmptyCondition(filter)
 	if err != nil {
 		return 0, err
@@ -555,9 +704,9 @@ func (b BaseMapper[T]) DeleteWithOptions(filter any, opts ...options.Lister[opti
 	if empty {
 		return 0, ErrEmptyCondition
 	}
-	coll, err := collection(b.model.CollectionName())
-	if err != nil {
-		return 0, err
-	}
-	return checkDeleteResult(coll.DeleteMany(context.Background(), filter, opts...))
+	err = b.execute("DeleteWithOptions", func(ctx context.Context, coll *mongo.Collection) (int64, error) {
+		deleted, err = checkDeleteResult(coll.DeleteMany(ctx, filter, opts...))
+		return deleted, err
+	})
+	return deleted, err
 }
@@ -0,0 +1,282 @@
+package mongostarter
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/event"
+)
+
+// DefaultMetricsBuckets 默认命令耗时直方图桶 单位秒
+var DefaultMetricsBuckets = []float64{0.001, 0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+
+var mongoMetrics *metricsRegistry
+
+// MetricsConfig 指标采集配置
+type MetricsConfig struct {
+	// 指标名称前缀 默认 mongo
+	Namespace string
+	// 命令耗时与连接等待直方图桶 单位秒 默认 DefaultMetricsBuckets
+	Buckets []float64
+}
+
+type commandLabels struct {
+	database   string
+	collection string
+	command    string
+}
+
+type histogram struct {
+	counts []uint64
+	sum    float64
+	count  uint64
+}
+
+type metricsRegistry struct {
+	namespace string
+	buckets   []float64
+
+	// 记录已开始但尚未结束的命令 key 为 RequestID
+	pending sync.Map
+
+	mu       sync.Mutex
+	commands map[commandLabels]*histogram
+	errors   map[commandLabels]uint64
+	waits    map[string]*histogram
+	// 每个地址上未关闭的连接池数量 凭据轮换期间新旧客户端共用同一地址的等待直方图
+	pools map[string]int
+}
+
+func newMetricsRegistry(config *MetricsConfig) *metricsRegistry {
+	namespace := config.Namespace
+	if namespace == "" {
+		namespace = "mongo"
+	}
+	buckets := config.Buckets
+	if len(buckets) == 0 {
+		buckets = DefaultMetricsBuckets
+	}
+	buckets = append([]float64(nil), buckets...)
+	sort.Float64s(buckets)
+	return &metricsRegistry{
+		namespace: namespace,
+		buckets:   buckets,
+		commands:  make(map[commandLabels]*histogram),
+		errors:    make(map[commandLabels]uint64),
+		waits:     make(map[string]*histogram),
+		pools:     make(map[string]int),
+	}
+}
+
+func (r *metricsRegistry) newHistogram() *histogram {
+	return &histogram{counts: make([]uint64, len(r.buckets))}
+}
+
+func (r *metricsRegistry) observe(h *histogram, duration time.Duration) {
+	seconds := duration.Seconds()
+	for i, bound := range r.buckets {
+		if seconds <= bound {
+			h.counts[i]++
+		}
+	}
+	h.sum += seconds
+	h.count++
+}
+
+func (r *metricsRegistry) wait(address string) *histogram {
+	h, ok := r.waits[address]
+	if !ok {
+		h = r.newHistogram()
+		r.waits[address] = h
+	}
+	return h
+}
+
+func (r *metricsRegistry) finishCommand(requestID int64, duration time.Duration, failed bool) {
+	value, ok := r.pending.LoadAndDelete(requestID)
+	if !ok {
+		return
+	}
+	labels := value.(commandLabels)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	h, ok := r.commands[labels]
+	if !ok {
+		h = r.newHistogram()
+		r.commands[labels] = h
+	}
+	r.observe(h, duration)
+	if failed {
+		r.errors[labels]++
+	}
+}
+
+func (r *metricsRegistry) commandMonitor() *event.CommandMonitor {
+	return &event.CommandMonitor{
+		Started: func(_ context.Context, evt *event.CommandStartedEvent) {
+			r.pending.Store(evt.RequestID, commandLabels{
+				database:   evt.DatabaseName,
+				collection: commandCollection(evt),
+				command:    evt.CommandName,
+			})
+		},
+		Succeeded: func(_ context.Context, evt *event.CommandSucceededEvent) {
+			r.finishCommand(evt.RequestID, evt.Duration, false)
+		},
+		Failed: func(_ context.Context, evt *event.CommandFailedEvent) {
+			r.finishCommand(evt.RequestID, evt.Duration, true)
+		},
+	}
+}
+
+func (r *metricsRegistry) poolMonitor() *event.PoolMonitor {
+	return &event.PoolMonitor{
+		Event: func(evt *event.PoolEvent) {
+			r.mu.Lock()
+			defer r.mu.Unlock()
+			switch evt.Type {
+			case event.ConnectionCheckedOut, event.ConnectionCheckOutFailed:
+				r.observe(r.wait(evt.Address), evt.Duration)
+			case event.ConnectionPoolCreated:
+				r.pools[evt.Address]++
+			case event.ConnectionPoolClosed:
+				// 旧客户端关闭连接池时新客户端仍在使用该地址 只在最后一个连接池关闭时清除
+				if r.pools[evt.Address]--; r.pools[evt.Address] <= 0 {
+					delete(r.pools, evt.Address)
+					delete(r.waits, evt.Address)
+				}
+			}
+		},
+	}
+}
+
+// writeTo 按 Prometheus 文本格式输出全部指标
+func (r *metricsRegistry) writeTo(w *bufio.Writer, pools []PoolStats) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	commandKeys := make([]commandLabels, 0, len(r.commands))
+	for labels := range r.commands {
+		commandKeys = append(commandKeys, labels)
+	}
+	sort.Slice(commandKeys, func(i, j int) bool {
+		a, b := commandKeys[i], commandKeys[j]
+		if a.database != b.database {
+			return a.database < b.database
+		}
+		if a.collection != b.collection {
+			return a.collection < b.collection
+		}
+		return a.command < b.command
+	})
+	addresses := make([]string, 0, len(r.waits))
+	for address := range r.waits {
+		addresses = append(addresses, address)
+	}
+	sort.Strings(addresses)
+
+	name := r.namespace + "_command_duration_seconds"
+	writeHeader(w, name, "histogram", "MongoDB command latency in seconds.")
+	for _, labels := range commandKeys {
+		r.writeHistogram(w, name, commandLabelPairs(labels), r.commands[labels])
+	}
+
+	name = r.namespace + "_command_errors_total"
+	writeHeader(w, name, "counter", "Total number of failed MongoDB commands.")
+	for _, labels := range commandKeys {
+		writeSample(w, name, commandLabelPairs(labels), float64(r.errors[labels]))
+	}
+
+	name = r.namespace + "_pool_connections_checked_out"
+	writeHeader(w, name, "gauge", "Number of connections currently checked out of the pool.")
+	for _, stats := range pools {
+		writeSample(w, name, []string{"address", stats.Address}, float64(stats.CheckedOut))
+	}
+
+	name = r.namespace + "_pool_connections_idle"
+	writeHeader(w, name, "gauge", "Number of idle connections in the pool.")
+	for _, stats := range pools {
+		writeSample(w, name, []string{"address", stats.Address}, float64(stats.Idle))
+	}
+
+	name = r.namespace + "_pool_checkout_failures_total"
+	writeHeader(w, name, "counter", "Total number of failed connection checkouts.")
+	for _, stats := range pools {
+		writeSample(w, name, []string{"address", stats.Address}, float64(stats.CheckoutFailures))
+	}
+
+	name = r.namespace + "_pool_wait_duration_seconds"
+	writeHeader(w, name, "histogram", "Time spent waiting to check out a connection in seconds.")
+	for _, address := range addresses {
+		r.writeHistogram(w, name, []string{"address", address}, r.waits[address])
+	}
+}
+
+func (r *metricsRegistry) writeHistogram(w *bufio.Writer, name string, labels []string, h *histogram) {
+	for i, bound := range r.buckets {
+		writeSample(w, name+"_bucket", append(labels, "le", formatFloat(bound)), float64(h.counts[i]))
+	}
+	writeSample(w, name+"_bucket", append(labels, "le", "+Inf"), float64(h.count))
+	writeSample(w, name+"_sum", labels, h.sum)
+	writeSample(w, name+"_count", labels, float64(h.count))
+}
+
+func commandLabelPairs(labels commandLabels) []string {
+	return []string{"database", labels.database, "collection", labels.collection, "command", labels.command}
+}
+
+func writeHeader(w *bufio.Writer, name, kind, help string) {
+	_, _ = fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n", name, help, name, kind)
+}
+
+// writeSample 输出单条样本 labels 为交替排列的标签名与标签值
+func writeSample(w *bufio.Writer, name string, labels []string, value float64) {
+	_, _ = w.WriteString(name)
+	if len(labels) > 0 {
+		_ = w.WriteByte('{')
+		for i := 0; i+1 < len(labels); i += 2 {
+			if i > 0 {
+				_ = w.WriteByte(',')
+			}
+			_, _ = w.WriteString(labels[i])
+			_, _ = w.WriteString(`="`)
+			_, _ = w.WriteString(labelValueReplacer.Replace(labels[i+1]))
+			_ = w.WriteByte('"')
+		}
+		_ = w.WriteByte('}')
+	}
+	_ = w.WriteByte(' ')
+	_, _ = w.WriteString(formatFloat(value))
+	_ = w.WriteByte('\n')
+}
+
+var labelValueReplacer = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+func formatFloat(value float64) string {
+	return strconv.FormatFloat(value, 'g', -1, 64)
+}
+
+// MetricsHandler 返回 Prometheus 文本格式的指标输出 Handler
+// 未启用指标采集或 starter 未启动时返回 503
+func MetricsHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		mongoLock.RLock()
+		registry, pool := mongoMetrics, mongoPool
+		mongoLock.RUnlock()
+		if registry == nil {
+			http.Error(w, ErrMetricsNotEnabled.Error(), http.StatusServiceUnavailable)
+			return
+		}
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		writer := bufio.NewWriter(w)
+		registry.writeTo(writer, pool.snapshot())
+		_ = writer.Flush()
+	})
+}
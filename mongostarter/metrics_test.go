@@ -0,0 +1,31 @@
+package mongostarter
+
+import (
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/event"
+)
+
+func TestPoolWaitsSurviveRetiredClient(t *testing.T) {
+	registry := newMetricsRegistry(&MetricsConfig{})
+	const address = "db-1:27017"
+	retired, current := registry.poolMonitor(), registry.poolMonitor()
+	retired.Event(&event.PoolEvent{Type: event.ConnectionPoolCreated, Address: address})
+	current.Event(&event.PoolEvent{Type: event.ConnectionPoolCreated, Address: address})
+	current.Event(&event.PoolEvent{Type: event.ConnectionCheckedOut, Address: address, Duration: time.Millisecond})
+
+	retired.Event(&event.PoolEvent{Type: event.ConnectionPoolClosed, Address: address})
+	if h := registry.waits[address]; h == nil || h.count != 1 {
+		t.Fatalf("expected the retired client's pool close to keep the wait histogram, got %+v", h)
+	}
+	current.Event(&event.PoolEvent{Type: event.ConnectionCheckedOut, Address: address, Duration: time.Millisecond})
+	if h := registry.waits[address]; h.count != 2 {
+		t.Fatalf("expected waits to keep accumulating, got %d", h.count)
+	}
+
+	current.Event(&event.PoolEvent{Type: event.ConnectionPoolClosed, Address: address})
+	if _, ok := registry.waits[address]; ok {
+		t.Fatal("expected the last pool close to remove the wait histogram")
+	}
+}
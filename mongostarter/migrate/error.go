@@ -0,0 +1,12 @@
+package migrate
+
+import "errors"
+
+var (
+	ErrInvalidMigration   = errors.New("invalid migration")
+	ErrDuplicateMigration = errors.New("duplicate migration id")
+	ErrUnknownTarget      = errors.New("unknown target migration")
+	ErrIrreversible       = errors.New("migration has no Down and cannot be rolled back")
+	ErrLockTimeout        = errors.New("waiting for migration lock timeout")
+	ErrLockLost           = errors.New("migration lock was lost")
+)
@@ -0,0 +1,119 @@
+package migrate
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/acexy/golang-toolkit/logger"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+// 锁文档的 _id
+const lockID = "migrate"
+
+// migrationLock 基于唯一 _id 的迁移锁 持有期间定时续期
+type migrationLock struct {
+	coll  *mongo.Collection
+	owner string
+	ttl   time.Duration
+
+	// 锁丢失时以 ErrLockLost 取消 迁移使用该上下文执行
+	ctx    context.Context
+	cancel context.CancelCauseFunc
+	done   chan struct{}
+}
+
+// acquireLock 获取迁移锁 锁被其他实例持有时等待 最长 timeout
+func acquireLock(ctx context.Context, coll *mongo.Collection, timeout, ttl time.Duration) (*migrationLock, error) {
+	hostname, _ := os.Hostname()
+	lock := &migrationLock{
+		coll:  coll,
+		owner: fmt.Sprintf("%s-%d-%d", hostname, os.Getpid(), time.Now().UnixNano()),
+		ttl:   ttl,
+		done:  make(chan struct{}),
+	}
+	deadline := time.Now().Add(timeout)
+	for {
+		acquired, err := lock.try(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if acquired {
+			break
+		}
+		if time.Now().After(deadline) {
+			return nil, ErrLockTimeout
+		}
+		logger.Logrus().Infoln("mongo migration lock is held by another instance, waiting")
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(500 * time.Millisecond):
+		}
+	}
+	lock.ctx, lock.cancel = context.WithCancelCause(ctx)
+	go lock.keepAlive()
+	return lock, nil
+}
+
+// try 锁文档不存在或已过期时获取锁 锁被持有时条件不匹配 upsert 因 _id 重复失败
+func (l *migrationLock) try(ctx context.Context) (bool, error) {
+	now := time.Now()
+	_, err := l.coll.UpdateOne(ctx,
+		bson.M{"_id": lockID, "expiresAt": bson.M{"$lt": now}},
+		bson.M{"$set": bson.M{"owner": l.owner, "lockedAt": now, "expiresAt": now.Add(l.ttl)}},
+		options.UpdateOne().SetUpsert(true))
+	if mongo.IsDuplicateKeyError(err) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// keepAlive 每隔 ttl/3 续期 续期失败说明锁已被抢占
+func (l *migrationLock) keepAlive() {
+	defer close(l.done)
+	ticker := time.NewTicker(l.ttl / 3)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-l.ctx.Done():
+			return
+		case <-ticker.C:
+			result, err := l.coll.UpdateOne(l.ctx,
+				bson.M{"_id": lockID, "owner": l.owner},
+				bson.M{"$set": bson.M{"expiresAt": time.Now().Add(l.ttl)}})
+			if err != nil {
+				logger.Logrus().WithError(err).Warnln("renew mongo migration lock failed")
+				continue
+			}
+			if result.MatchedCount == 0 {
+				l.cancel(ErrLockLost)
+				return
+			}
+		}
+	}
+}
+
+// wrap 锁丢失导致的失败附加 ErrLockLost
+func (l *migrationLock) wrap(err error) error {
+	if errors.Is(context.Cause(l.ctx), ErrLockLost) && !errors.Is(err, ErrLockLost) {
+		return fmt.Errorf("%w: %w", ErrLockLost, err)
+	}
+	return err
+}
+
+// release 停止续期并删除自己持有的锁文档
+func (l *migrationLock) release() {
+	l.cancel(nil)
+	<-l.done
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if _, err := l.coll.DeleteOne(ctx, bson.M{"_id": lockID, "owner": l.owner}); err != nil {
+		logger.Logrus().WithError(err).Warnln("release mongo migration lock failed")
+	}
+}
@@ -0,0 +1,247 @@
+package migrate
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/acexy/golang-toolkit/logger"
+	"github.com/golang-acexy/starter-mongo/mongostarter"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+var (
+	registry     []Migration
+	registryLock sync.RWMutex
+)
+
+// Migration 一次数据迁移
+type Migration struct {
+	// 迁移标识 按字符串顺序执行 建议使用时间前缀 如 20261019_backfill_status
+	ID string
+	// 迁移说明
+	Description string
+	// 执行迁移
+	Up func(ctx context.Context, db *mongo.Database) error
+	// 回滚迁移 为空时不可回滚
+	Down func(ctx context.Context, db *mongo.Database) error
+}
+
+// Register 注册迁移 通常在 init 中调用 ID 为空、Up 为空或 ID 重复时 panic
+func Register(migrations ...Migration) {
+	registryLock.Lock()
+	defer registryLock.Unlock()
+	for _, migration := range migrations {
+		if migration.ID == "" || migration.Up == nil {
+			panic(fmt.Errorf("%w: id and Up are required", ErrInvalidMigration))
+		}
+		for _, registered := range registry {
+			if registered.ID == migration.ID {
+				panic(fmt.Errorf("%w: %s", ErrDuplicateMigration, migration.ID))
+			}
+		}
+		registry = append(registry, migration)
+	}
+}
+
+// Registered 按 ID 排序返回已注册的迁移
+func Registered() []Migration {
+	registryLock.RLock()
+	defer registryLock.RUnlock()
+	result := append([]Migration(nil), registry...)
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].ID < result[j].ID
+	})
+	return result
+}
+
+// Config 迁移执行配置
+type Config struct {
+	// 迁移所在数据库 为空时使用 mongostarter 的默认数据库
+	Database string
+	// 迁移记录集合 默认 schema_migrations 按 mongostarter 的命名策略转换
+	Collection string
+	// 迁移锁集合 默认 schema_migrations_lock 按 mongostarter 的命名策略转换
+	LockCollection string
+	// 目标版本 为空时执行全部未执行的迁移
+	// 不为空时执行 ID 不大于目标的迁移 并按倒序回滚 ID 大于目标的已执行迁移
+	Target string
+	// 只计算执行计划 不执行迁移 不写入记录
+	DryRun bool
+	// 等待其他实例释放迁移锁的最长时间 默认 1 分钟
+	LockTimeout time.Duration
+	// 迁移锁的有效期 持有期间自动续期 实例异常退出后锁在过期后可被抢占 默认 1 分钟
+	LockTTL time.Duration
+}
+
+func (c Config) withDefaults() Config {
+	if c.Collection == "" {
+		c.Collection = "schema_migrations"
+	}
+	if c.LockCollection == "" {
+		c.LockCollection = "schema_migrations_lock"
+	}
+	if c.LockTimeout <= 0 {
+		c.LockTimeout = time.Minute
+	}
+	if c.LockTTL <= 0 {
+		c.LockTTL = time.Minute
+	}
+	return c
+}
+
+// Result 迁移执行结果 DryRun 时为计划执行的迁移
+type Result struct {
+	// 执行的迁移 按执行顺序
+	Applied []string
+	// 回滚的迁移 按执行顺序
+	RolledBack []string
+	// 是否为演练
+	DryRun bool
+}
+
+// record 迁移记录文档
+type record struct {
+	ID          string    `bson:"_id"`
+	Description string    `bson:"description,omitempty"`
+	AppliedAt   time.Time `bson:"appliedAt"`
+	DurationMS  int64     `bson:"durationMs"`
+}
+
+// Run 按配置执行已注册的迁移 持有迁移锁期间其他实例等待
+// 失败时已成功的迁移保留记录 Result 中包含失败前完成的迁移
+func Run(ctx context.Context, config Config) (*Result, error) {
+	config = config.withDefaults()
+	db := mongostarter.RawDatabase(databaseArgs(config.Database)...)
+	if db == nil {
+		return nil, mongostarter.ErrMongoStarterNotStarted
+	}
+	migrations := Registered()
+	if config.Target != "" && !containsTarget(migrations, config.Target) {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownTarget, config.Target)
+	}
+	records := db.Collection(mongostarter.ApplyNaming(config.Collection))
+	result := &Result{DryRun: config.DryRun}
+	if config.DryRun {
+		applied, err := appliedIDs(ctx, records)
+		if err != nil {
+			return nil, err
+		}
+		up, down, err := plan(migrations, applied, config.Target)
+		if err != nil {
+			return nil, err
+		}
+		result.Applied, result.RolledBack = ids(up), ids(down)
+		return result, nil
+	}
+
+	lock, err := acquireLock(ctx, db.Collection(mongostarter.ApplyNaming(config.LockCollection)), config.LockTimeout, config.LockTTL)
+	if err != nil {
+		return nil, err
+	}
+	defer lock.release()
+	ctx = lock.ctx
+
+	// 获得锁后重新读取记录 其他实例可能已完成迁移
+	applied, err := appliedIDs(ctx, records)
+	if err != nil {
+		return nil, err
+	}
+	up, down, err := plan(migrations, applied, config.Target)
+	if err != nil {
+		return nil, err
+	}
+	for _, migration := range down {
+		start := time.Now()
+		if err = migration.Down(ctx, db); err != nil {
+			return result, lock.wrap(fmt.Errorf("rollback migration %s: %w", migration.ID, err))
+		}
+		if _, err = records.DeleteOne(ctx, bson.M{"_id": migration.ID}); err != nil {
+			return result, lock.wrap(err)
+		}
+		result.RolledBack = append(result.RolledBack, migration.ID)
+		logger.Logrus().Infof("mongo migration %s rolled back in %s", migration.ID, time.Since(start))
+	}
+	for _, migration := range up {
+		start := time.Now()
+		if err = migration.Up(ctx, db); err != nil {
+			return result, lock.wrap(fmt.Errorf("apply migration %s: %w", migration.ID, err))
+		}
+		duration := time.Since(start)
+		_, err = records.ReplaceOne(ctx, bson.M{"_id": migration.ID}, record{
+			ID:          migration.ID,
+			Description: migration.Description,
+			AppliedAt:   time.Now(),
+			DurationMS:  duration.Milliseconds(),
+		}, options.Replace().SetUpsert(true))
+		if err != nil {
+			return result, lock.wrap(err)
+		}
+		result.Applied = append(result.Applied, migration.ID)
+		logger.Logrus().Infof("mongo migration %s applied in %s", migration.ID, duration)
+	}
+	return result, nil
+}
+
+// plan 计算需要执行与回滚的迁移 回滚按倒序排列
+func plan(migrations []Migration, applied map[string]bool, target string) (up, down []Migration, err error) {
+	for _, migration := range migrations {
+		if target != "" && migration.ID > target {
+			if applied[migration.ID] {
+				if migration.Down == nil {
+					return nil, nil, fmt.Errorf("%w: %s", ErrIrreversible, migration.ID)
+				}
+				down = append([]Migration{migration}, down...)
+			}
+			continue
+		}
+		if !applied[migration.ID] {
+			up = append(up, migration)
+		}
+	}
+	return up, down, nil
+}
+
+func appliedIDs(ctx context.Context, records *mongo.Collection) (map[string]bool, error) {
+	cursor, err := records.Find(ctx, bson.M{}, options.Find().SetProjection(bson.M{"_id": 1}))
+	if err != nil {
+		return nil, err
+	}
+	var documents []record
+	if err = cursor.All(ctx, &documents); err != nil {
+		return nil, err
+	}
+	result := make(map[string]bool, len(documents))
+	for _, document := range documents {
+		result[document.ID] = true
+	}
+	return result, nil
+}
+
+func containsTarget(migrations []Migration, target string) bool {
+	for _, migration := range migrations {
+		if migration.ID == target {
+			return true
+		}
+	}
+	return false
+}
+
+func ids(migrations []Migration) []string {
+	result := make([]string, 0, len(migrations))
+	for _, migration := range migrations {
+		result = append(result, migration.ID)
+	}
+	return result
+}
+
+func databaseArgs(database string) []string {
+	if database == "" {
+		return nil
+	}
+	return []string{database}
+}
@@ -0,0 +1,53 @@
+package migrate
+
+import (
+	"context"
+	"time"
+
+	"github.com/golang-acexy/starter-parent/parent"
+)
+
+// MigrationStarter 在启动阶段执行迁移 需要在 MongoStarter 之后注册
+// 迁移失败时启动失败 应用不会开始对外服务
+type MigrationStarter struct {
+	Config     Config
+	LazyConfig func() Config
+
+	config           *Config
+	MigrationSetting *parent.Setting
+}
+
+func (m *MigrationStarter) getConfig() *Config {
+	if m.config == nil {
+		var config Config
+		if m.LazyConfig != nil {
+			config = m.LazyConfig()
+		} else {
+			config = m.Config
+		}
+		m.config = &config
+	}
+	return m.config
+}
+
+func (m *MigrationStarter) Setting() *parent.Setting {
+	if m.MigrationSetting != nil {
+		return m.MigrationSetting
+	}
+	return parent.NewSetting(
+		"Mongo-Migration-Starter",
+		false,
+		20,
+		false,
+		time.Second,
+		nil)
+}
+
+// Start 执行迁移 返回 *Result
+func (m *MigrationStarter) Start() (any, error) {
+	return Run(context.Background(), *m.getConfig())
+}
+
+func (m *MigrationStarter) Stop(maxWaitTime time.Duration) (gracefully, stopped bool, err error) {
+	return true, true, nil
+}
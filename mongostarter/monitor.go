@@ -0,0 +1,166 @@
+package mongostarter
+
+import (
+	"context"
+
+	"go.mongodb.org/mongo-driver/v2/event"
+)
+
+// combineCommandMonitors 合并多个命令监听器 全部为空时返回 nil
+func combineCommandMonitors(monitors ...*event.CommandMonitor) *event.CommandMonitor {
+	active := make([]*event.CommandMonitor, 0, len(monitors))
+	for _, monitor := range monitors {
+		if monitor != nil {
+			active = append(active, monitor)
+		}
+	}
+	switch len(active) {
+	case 0:
+		return nil
+	case 1:
+		return active[0]
+	}
+	return &event.CommandMonitor{
+		Started: func(ctx context.Context, evt *event.CommandStartedEvent) {
+			for _, monitor := range active {
+				if monitor.Started != nil {
+					monitor.Started(ctx, evt)
+				}
+			}
+		},
+		Succeeded: func(ctx context.Context, evt *event.CommandSucceededEvent) {
+			for _, monitor := range active {
+				if monitor.Succeeded != nil {
+					monitor.Succeeded(ctx, evt)
+				}
+			}
+		},
+		Failed: func(ctx context.Context, evt *event.CommandFailedEvent) {
+			for _, monitor := range active {
+				if monitor.Failed != nil {
+					monitor.Failed(ctx, evt)
+				}
+			}
+		},
+	}
+}
+
+// combinePoolMonitors 合并多个连接池监听器 全部为空时返回 nil
+func combinePoolMonitors(monitors ...*event.PoolMonitor) *event.PoolMonitor {
+	active := make([]*event.PoolMonitor, 0, len(monitors))
+	for _, monitor := range monitors {
+		if monitor != nil && monitor.Event != nil {
+			active = append(active, monitor)
+		}
+	}
+	switch len(active) {
+	case 0:
+		return nil
+	case 1:
+		return active[0]
+	}
+	return &event.PoolMonitor{
+		Event: func(evt *event.PoolEvent) {
+			for _, monitor := range active {
+				monitor.Event(evt)
+			}
+		},
+	}
+}
+
+// combineServerMonitors 合并多个服务端监听器 全部为空时返回 nil
+func combineServerMonitors(monitors ...*event.ServerMonitor) *event.ServerMonitor {
+	active := make([]*event.ServerMonitor, 0, len(monitors))
+	for _, monitor := range monitors {
+		if monitor != nil {
+			active = append(active, monitor)
+		}
+	}
+	switch len(active) {
+	case 0:
+		return nil
+	case 1:
+		return active[0]
+	}
+	return &event.ServerMonitor{
+		ServerDescriptionChanged: func(evt *event.ServerDescriptionChangedEvent) {
+			for _, monitor := range active {
+				if monitor.ServerDescriptionChanged != nil {
+					monitor.ServerDescriptionChanged(evt)
+				}
+			}
+		},
+		ServerOpening: func(evt *event.ServerOpeningEvent) {
+			for _, monitor := range active {
+				if monitor.ServerOpening != nil {
+					monitor.ServerOpening(evt)
+				}
+			}
+		},
+		ServerClosed: func(evt *event.ServerClosedEvent) {
+			for _, monitor := range active {
+				if monitor.ServerClosed != nil {
+					monitor.ServerClosed(evt)
+				}
+			}
+		},
+		TopologyDescriptionChanged: func(evt *event.TopologyDescriptionChangedEvent) {
+			for _, monitor := range active {
+				if monitor.TopologyDescriptionChanged != nil {
+					monitor.TopologyDescriptionChanged(evt)
+				}
+			}
+		},
+		TopologyOpening: func(evt *event.TopologyOpeningEvent) {
+			for _, monitor := range active {
+				if monitor.TopologyOpening != nil {
+					monitor.TopologyOpening(evt)
+				}
+			}
+		},
+		TopologyClosed: func(evt *event.TopologyClosedEvent) {
+			for _, monitor := range active {
+				if monitor.TopologyClosed != nil {
+					monitor.TopologyClosed(evt)
+				}
+			}
+		},
+		ServerHeartbeatStarted: func(evt *event.ServerHeartbeatStartedEvent) {
+			for _, monitor := range active {
+				if monitor.ServerHeartbeatStarted != nil {
+					monitor.ServerHeartbeatStarted(evt)
+				}
+			}
+		},
+		ServerHeartbeatSucceeded: func(evt *event.ServerHeartbeatSucceededEvent) {
+			for _, monitor := range active {
+				if monitor.ServerHeartbeatSucceeded != nil {
+					monitor.ServerHeartbeatSucceeded(evt)
+				}
+			}
+		},
+		ServerHeartbeatFailed: func(evt *event.ServerHeartbeatFailedEvent) {
+			for _, monitor := range active {
+				if monitor.ServerHeartbeatFailed != nil {
+					monitor.ServerHeartbeatFailed(evt)
+				}
+			}
+		},
+	}
+}
+
+// commandCollection 从命令内容中解析目标集合名称
+func commandCollection(evt *event.CommandStartedEvent) string {
+	if value, err := evt.Command.LookupErr(evt.CommandName); err == nil {
+		if name, ok := value.StringValueOK(); ok {
+			return name
+		}
+	}
+	// getMore 等命令的集合名称位于 collection 字段
+	if value, err := evt.Command.LookupErr("collection"); err == nil {
+		if name, ok := value.StringValueOK(); ok {
+			return name
+		}
+	}
+	return ""
+}
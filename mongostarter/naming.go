@@ -0,0 +1,173 @@
+package mongostarter
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"unicode"
+)
+
+// 当前生效的集合命名策略 受 mongoLock 保护
+var namingStrategy *NamingStrategy
+
+// NameCase 集合名称大小写转换方式
+type NameCase string
+
+const (
+	// NameCaseKeep 保持原样
+	NameCaseKeep NameCase = ""
+	// NameCaseSnake 转换为 snake_case
+	NameCaseSnake NameCase = "snake"
+	// NameCaseCamel 转换为 lowerCamelCase
+	NameCaseCamel NameCase = "camel"
+	// NameCaseLower 转换为全小写
+	NameCaseLower NameCase = "lower"
+)
+
+// NamingStrategy 全局集合命名策略 Mapper、RawCollection 以及索引、校验器、集合预创建同步均使用转换后的名称
+type NamingStrategy struct {
+	// 集合名称前缀 如 stg_
+	Prefix string
+	// 集合名称后缀
+	Suffix string
+	// 大小写转换 在添加前缀、后缀之前执行
+	Case NameCase
+}
+
+// AutoNamed 嵌入到模型中时按结构体类型推导集合名称 嵌入时需声明 bson:"-" 避免写入空文档字段
+// 推导的名称使用命名策略的大小写转换 未配置时转换为 snake_case
+type AutoNamed struct{}
+
+// CollectionName 返回空字符串 表示由结构体类型推导集合名称
+func (AutoNamed) CollectionName() string {
+	return ""
+}
+
+// ApplyNaming 按当前命名策略转换集合名称 未启动或未配置命名策略时原样返回
+func ApplyNaming(collection string) string {
+	mongoLock.RLock()
+	defer mongoLock.RUnlock()
+	return namingStrategy.apply(collection)
+}
+
+// currentNaming 获取当前命名策略
+func currentNaming() *NamingStrategy {
+	mongoLock.RLock()
+	defer mongoLock.RUnlock()
+	return namingStrategy
+}
+
+// apply 转换集合名称 nil 策略原样返回
+func (n *NamingStrategy) apply(collection string) string {
+	if n == nil {
+		return collection
+	}
+	return n.Prefix + convertCase(collection, n.Case) + n.Suffix
+}
+
+// nameCase 大小写转换方式 nil 策略保持原样
+func (n *NamingStrategy) nameCase() NameCase {
+	if n == nil {
+		return NameCaseKeep
+	}
+	return n.Case
+}
+
+func (n *NamingStrategy) validate(result *ConfigError) {
+	if strings.ContainsAny(n.Prefix, "$\x00") {
+		result.add("Naming.Prefix", errors.New("must not contain '$' or null characters"))
+	}
+	if strings.ContainsAny(n.Suffix, "$\x00") {
+		result.add("Naming.Suffix", errors.New("must not contain '$' or null characters"))
+	}
+	switch n.Case {
+	case NameCaseKeep, NameCaseSnake, NameCaseCamel, NameCaseLower:
+	default:
+		result.add("Naming.Case", errors.New("unsupported name case "+string(n.Case)))
+	}
+}
+
+// baseCollectionName 模型声明的集合名称 声明为空时按结构体类型推导 不包含命名策略的前缀与后缀
+func baseCollectionName(model Model, nameCase NameCase) string {
+	if name := model.CollectionName(); name != "" {
+		return name
+	}
+	t := reflect.TypeOf(model)
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	name := t.Name()
+	// 泛型类型名称包含类型参数
+	if i := strings.IndexByte(name, '['); i >= 0 {
+		name = name[:i]
+	}
+	if nameCase == NameCaseKeep {
+		return convertCase(name, NameCaseSnake)
+	}
+	return name
+}
+
+// modelCollectionName 模型对应的实际集合名称
+func modelCollectionName(model Model, naming *NamingStrategy) string {
+	return naming.apply(baseCollectionName(model, naming.nameCase()))
+}
+
+// convertCase 转换名称大小写 以 . 分隔的各段分别转换
+func convertCase(name string, nameCase NameCase) string {
+	if nameCase == NameCaseKeep || nameCase == NameCaseLower {
+		return convertSegment(name, nameCase)
+	}
+	segments := strings.Split(name, ".")
+	for i, segment := range segments {
+		segments[i] = convertSegment(segment, nameCase)
+	}
+	return strings.Join(segments, ".")
+}
+
+func convertSegment(name string, nameCase NameCase) string {
+	switch nameCase {
+	case NameCaseSnake:
+		return strings.Join(nameWords(name), "_")
+	case NameCaseCamel:
+		words := nameWords(name)
+		for i := 1; i < len(words); i++ {
+			runes := []rune(words[i])
+			runes[0] = unicode.ToUpper(runes[0])
+			words[i] = string(runes)
+		}
+		return strings.Join(words, "")
+	case NameCaseLower:
+		return strings.ToLower(name)
+	default:
+		return name
+	}
+}
+
+// nameWords 按分隔符与大小写边界拆分为小写单词 如 HTTPRequestLog 拆分为 http、request、log
+func nameWords(name string) []string {
+	var words []string
+	var current []rune
+	runes := []rune(name)
+	flush := func() {
+		if len(current) > 0 {
+			words = append(words, strings.ToLower(string(current)))
+			current = current[:0]
+		}
+	}
+	for i, r := range runes {
+		if r == '_' || r == '-' || r == ' ' {
+			flush()
+			continue
+		}
+		if unicode.IsUpper(r) && i > 0 {
+			previous := runes[i-1]
+			nextLower := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+			if unicode.IsLower(previous) || unicode.IsDigit(previous) || (unicode.IsUpper(previous) && nextLower) {
+				flush()
+			}
+		}
+		current = append(current, r)
+	}
+	flush()
+	return words
+}
@@ -0,0 +1,24 @@
+package mongostarter
+
+import (
+	"context"
+	"crypto/tls"
+
+	"go.mongodb.org/mongo-driver/v2/x/mongo/driver/ocsp"
+)
+
+// ocspVerifier 检查服务端证书的 OCSP 吊销状态 与驱动内置 TLS 的检查一致
+// 驱动未公开 OCSP 校验 这里依赖 x/mongo/driver/ocsp 该包不保证兼容性 其版本由 go.mod 中的驱动版本固定
+// 升级驱动时需要确认 NewCache 与 Verify 的签名 对 x/ 包的依赖只允许出现在本文件中
+type ocspVerifier struct {
+	cache ocsp.Cache
+}
+
+func newOCSPVerifier() *ocspVerifier {
+	return &ocspVerifier{cache: ocsp.NewCache()}
+}
+
+// verify 优先使用服务端装订的 OCSP 响应 否则请求证书中的 OCSP 地址 证书已吊销时返回错误
+func (v *ocspVerifier) verify(ctx context.Context, state tls.ConnectionState) error {
+	return ocsp.Verify(ctx, state, &ocsp.VerifyOptions{Cache: v.cache})
+}
@@ -0,0 +1,131 @@
+package mongostarter
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// 部分更新时 Optional 的 null 使用 $unset 删除字段 受 mongoLock 保护
+var unsetNullOptionals bool
+
+type optionalState uint8
+
+const (
+	optionalUnset optionalState = iota
+	optionalNull
+	optionalValue
+)
+
+// Optional 三态字段 区分未设置、null 与有值
+// 零值为未设置 IsZero 返回 true 配合 omitempty 或 omitzero 标签在编码时省略
+// 未设置与 null 均编码为 BSON/JSON null 解码时 null 为 null 状态 缺失字段保持未设置
+type Optional[T any] struct {
+	value T
+	state optionalState
+}
+
+// Some 创建有值的 Optional
+func Some[T any](value T) Optional[T] {
+	return Optional[T]{value: value, state: optionalValue}
+}
+
+// Null 创建值为 null 的 Optional
+func Null[T any]() Optional[T] {
+	return Optional[T]{state: optionalNull}
+}
+
+// IsSet 是否已设置 null 也视为已设置
+func (o Optional[T]) IsSet() bool {
+	return o.state != optionalUnset
+}
+
+// IsNull 是否为 null
+func (o Optional[T]) IsNull() bool {
+	return o.state == optionalNull
+}
+
+// IsZero 是否未设置
+func (o Optional[T]) IsZero() bool {
+	return o.state == optionalUnset
+}
+
+// Get 获取值 未设置或为 null 时 ok 为 false
+func (o Optional[T]) Get() (value T, ok bool) {
+	return o.value, o.state == optionalValue
+}
+
+// OrElse 有值时返回值 否则返回 fallback
+func (o Optional[T]) OrElse(fallback T) T {
+	if o.state == optionalValue {
+		return o.value
+	}
+	return fallback
+}
+
+func (o Optional[T]) MarshalBSONValue() (typ byte, data []byte, err error) {
+	if o.state != optionalValue {
+		return byte(bson.TypeNull), nil, nil
+	}
+	valueType, data, err := bson.MarshalValue(o.value)
+	return byte(valueType), data, err
+}
+
+func (o *Optional[T]) UnmarshalBSONValue(typ byte, data []byte) error {
+	if bson.Type(typ) == bson.TypeNull || bson.Type(typ) == bson.TypeUndefined {
+		*o = Null[T]()
+		return nil
+	}
+	var value T
+	if err := bson.UnmarshalValue(bson.Type(typ), data, &value); err != nil {
+		return err
+	}
+	*o = Some(value)
+	return nil
+}
+
+func (o Optional[T]) MarshalJSON() ([]byte, error) {
+	if o.state != optionalValue {
+		return []byte("null"), nil
+	}
+	return json.Marshal(o.value)
+}
+
+func (o *Optional[T]) UnmarshalJSON(data []byte) error {
+	if bytes.Equal(bytes.TrimSpace(data), []byte("null")) {
+		*o = Null[T]()
+		return nil
+	}
+	var value T
+	if err := json.Unmarshal(data, &value); err != nil {
+		return err
+	}
+	*o = Some(value)
+	return nil
+}
+
+// optional 供条件与更新构建器识别三态字段
+func (o Optional[T]) optional() (optionalState, any) {
+	return o.state, o.value
+}
+
+// optionalField 由 Optional 实现
+type optionalField interface {
+	optional() (optionalState, any)
+}
+
+var optionalFieldType = reflect.TypeFor[optionalField]()
+
+// optionalOf 读取 Optional 或 *Optional 字段的状态 nil 的 *Optional 视为未设置 ok 为 false 表示不是 Optional
+func optionalOf(field reflect.Value) (state optionalState, value any, ok bool) {
+	if !field.IsValid() || !field.CanInterface() || !field.Type().Implements(optionalFieldType) {
+		return optionalUnset, nil, false
+	}
+	if field.Kind() == reflect.Ptr && field.IsNil() {
+		return optionalUnset, nil, true
+	}
+	state, value = field.Interface().(optionalField).optional()
+	return state, value, true
+}
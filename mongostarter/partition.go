@@ -0,0 +1,486 @@
+package mongostarter
+
+import (
+	"bytes"
+	"cmp"
+	"context"
+	"fmt"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/acexy/golang-toolkit/util/coll"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+// CollectionResolver 模型实现该接口时动态解析集合名称 返回空字符串时使用 CollectionName
+// 插入操作时 entity 为写入的实体 其他操作为 nil 此时只能依据 ctx 解析
+type CollectionResolver[T Model] interface {
+	ResolveCollection(ctx context.Context, entity *T) string
+}
+
+type collectionContextKey struct{}
+
+// WithCollectionName 返回携带集合名称的 context 用于指定单次 Mapper 调用的集合 优先于模型解析
+func WithCollectionName(ctx context.Context, collection string) context.Context {
+	return context.WithValue(ctx, collectionContextKey{}, collection)
+}
+
+// WithCollection 返回绑定指定集合的 Mapper 副本 优先于 context 与模型解析 空字符串恢复默认解析
+func (b BaseMapper[T]) WithCollection(collection string) BaseMapper[T] {
+	b.collection = collection
+	return b
+}
+
+// collectionName 按 Mapper、context、模型解析、CollectionName 的顺序解析集合名称 不包含命名策略的前缀与后缀
+func (b BaseMapper[T]) collectionName(ctx context.Context, entity *T) string {
+	if b.collection != "" {
+		return b.collection
+	}
+	if collection, _ := ctx.Value(collectionContextKey{}).(string); collection != "" {
+		return collection
+	}
+	if resolver, ok := any(b.model).(CollectionResolver[T]); ok {
+		if collection := resolver.ResolveCollection(ctx, entity); collection != "" {
+			return collection
+		}
+	}
+	return baseCollectionName(b.model, currentNaming().nameCase())
+}
+
+// collectionGroup 属于同一集合的待插入实体 indexes 为实体在原始切片中的位置
+type collectionGroup[T Model] struct {
+	collection string
+	entities   []*T
+	indexes    []int
+}
+
+// groupByCollection 按解析出的集合名称对实体分组 分组按首次出现的顺序排列
+func (b BaseMapper[T]) groupByCollection(entities []*T) []*collectionGroup[T] {
+	ctx := b.operationContext()
+	var groups []*collectionGroup[T]
+	byName := make(map[string]*collectionGroup[T])
+	for i, entity := range entities {
+		name := b.collectionName(ctx, entity)
+		group, ok := byName[name]
+		if !ok {
+			group = &collectionGroup[T]{collection: name}
+			byName[name] = group
+			groups = append(groups, group)
+		}
+		group.entities = append(group.entities, entity)
+		group.indexes = append(group.indexes, i)
+	}
+	return groups
+}
+
+// PartitionPeriod 分区周期
+type PartitionPeriod string
+
+const (
+	PartitionDaily   PartitionPeriod = "daily"
+	PartitionMonthly PartitionPeriod = "monthly"
+	PartitionYearly  PartitionPeriod = "yearly"
+)
+
+// Partitioning 按时间分区的集合命名规则 如 startup_log_202610
+type Partitioning struct {
+	// 集合名称前缀
+	Prefix string
+	// 分区周期 默认按月
+	Period PartitionPeriod
+	// 前缀与时间之间的分隔符 默认 _
+	Separator string
+	// 计算分区使用的时区 默认 UTC
+	Location *time.Location
+}
+
+// Collection 获取时间所在分区的集合名称
+func (p Partitioning) Collection(t time.Time) string {
+	separator := p.Separator
+	if separator == "" {
+		separator = "_"
+	}
+	return p.Prefix + separator + p.start(t).Format(p.layout())
+}
+
+// Collections 获取 [from, to] 时间范围内全部分区的集合名称 按时间升序排列
+func (p Partitioning) Collections(from, to time.Time) []string {
+	if to.Before(from) {
+		return nil
+	}
+	end := p.start(to)
+	var collections []string
+	for current := p.start(from); !current.After(end); current = p.next(current) {
+		collections = append(collections, p.Collection(current))
+	}
+	return collections
+}
+
+func (p Partitioning) layout() string {
+	switch p.Period {
+	case PartitionDaily:
+		return "20060102"
+	case PartitionYearly:
+		return "2006"
+	default:
+		return "200601"
+	}
+}
+
+// start 时间所在分区的起始时间
+func (p Partitioning) start(t time.Time) time.Time {
+	location := p.Location
+	if location == nil {
+		location = time.UTC
+	}
+	t = t.In(location)
+	switch p.Period {
+	case PartitionDaily:
+		return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, location)
+	case PartitionYearly:
+		return time.Date(t.Year(), time.January, 1, 0, 0, 0, 0, location)
+	default:
+		return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, location)
+	}
+}
+
+func (p Partitioning) next(start time.Time) time.Time {
+	switch p.Period {
+	case PartitionDaily:
+		return start.AddDate(0, 0, 1)
+	case PartitionYearly:
+		return start.AddDate(1, 0, 0)
+	default:
+		return start.AddDate(0, 1, 0)
+	}
+}
+
+// 跨集合查询时同时执行的最大集合数
+const fanOutConcurrency = 4
+
+// AcrossQuery 跨集合查询参数
+type AcrossQuery struct {
+	// 排序规则 为空时按集合顺序拼接
+	OrderBy []*OrderBy
+	// 最多返回的条数 0 表示全部 每个集合最多读取 Limit 条
+	Limit int64
+	// 只查询的数据库字段 排序字段会临时加入投影 合并后从结果中移除
+	SpecifyColumns []string
+}
+
+// partitionDocument 分区查询结果 keys 为排序字段的值 用于归并
+type partitionDocument[T Model] struct {
+	keys   []bson.RawValue
+	entity *T
+}
+
+// SelectAcross 在多个集合中并发查询并合并结果 OrderBy 非空时各集合在服务端排序后归并 否则按集合顺序拼接
+// 不存在的集合视为空集合
+func (b BaseMapper[T]) SelectAcross(collections []string, filter any, query AcrossQuery, result *[]*T) error {
+	if query.Limit < 0 {
+		return fmt.Errorf("%w: limit must not be negative", ErrInvalidAcrossQuery)
+	}
+	if result == nil {
+		return fmt.Errorf("%w: result must not be nil", ErrInvalidAcrossQuery)
+	}
+	opt := options.Find()
+	var injected []string
+	if len(query.SpecifyColumns) > 0 {
+		columns := slices.Clone(query.SpecifyColumns)
+		for _, order := range query.OrderBy {
+			if !coll.SliceContains(columns, order.Column) {
+				columns = append(columns, order.Column)
+				injected = append(injected, order.Column)
+			}
+		}
+		opt = specifyColumnsOpt(columns...)
+	}
+	if len(query.OrderBy) > 0 {
+		setOrderBy(&opt, query.OrderBy)
+	}
+	if query.Limit > 0 {
+		opt.SetLimit(query.Limit)
+	}
+	partitions := make([][]partitionDocument[T], len(collections))
+	err := b.fanOut(collections, "SelectAcross", func(i int, ctx context.Context, coll *mongo.Collection) (int64, error) {
+		cursor, err := coll.Find(ctx, filter, opt)
+		if err != nil {
+			return 0, err
+		}
+		defer cursor.Close(ctx)
+		for cursor.Next(ctx) {
+			keys := sortKeys(cursor.Current, query.OrderBy)
+			if len(injected) > 0 {
+				if cursor.Current, err = withoutPaths(cursor.Current, injected); err != nil {
+					return 0, err
+				}
+			}
+			entity := new(T)
+			if err = cursor.Decode(entity); err != nil {
+				return 0, err
+			}
+			partitions[i] = append(partitions[i], partitionDocument[T]{keys: keys, entity: entity})
+		}
+		return int64(len(partitions[i])), cursor.Err()
+	})
+	if err != nil {
+		return err
+	}
+	*result = mergePartitions(partitions, query.OrderBy, query.Limit)
+	return nil
+}
+
+// CountAcross 在多个集合中并发统计数据总数
+func (b BaseMapper[T]) CountAcross(collections []string, filter any) (int64, error) {
+	counts := make([]int64, len(collections))
+	err := b.fanOut(collections, "CountAcross", func(i int, ctx context.Context, coll *mongo.Collection) (int64, error) {
+		count, err := coll.CountDocuments(ctx, filter)
+		counts[i] = count
+		return count, err
+	})
+	if err != nil {
+		return 0, err
+	}
+	var total int64
+	for _, count := range counts {
+		total += count
+	}
+	return total, nil
+}
+
+// fanOut 在每个集合上执行 最多 fanOutConcurrency 个集合并发 任一集合失败时取消其余查询并返回该错误
+func (b BaseMapper[T]) fanOut(collections []string, operation string, fn func(i int, ctx context.Context, coll *mongo.Collection) (int64, error)) error {
+	ctx, cancel := context.WithCancel(b.operationContext())
+	defer cancel()
+	mapper := b.WithContext(ctx)
+	var (
+		mu       sync.Mutex
+		firstErr error
+		wg       sync.WaitGroup
+	)
+	semaphore := make(chan struct{}, fanOutConcurrency)
+	for i, collection := range collections {
+		select {
+		case semaphore <- struct{}{}:
+		case <-ctx.Done():
+		}
+		if ctx.Err() != nil {
+			break
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() { <-semaphore }()
+			err := mapper.executeIn(collection, operation, func(ctx context.Context, coll *mongo.Collection) (int64, error) {
+				return fn(i, ctx, coll)
+			})
+			if err != nil {
+				mu.Lock()
+				if firstErr == nil {
+					firstErr = err
+					cancel()
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+	if firstErr != nil {
+		return firstErr
+	}
+	return ctx.Err()
+}
+
+// mergePartitions 按排序规则归并各集合已排序的结果 排序值相同时靠前的集合优先 limit 为 0 时不限制条数
+func mergePartitions[T Model](partitions [][]partitionDocument[T], orderBy []*OrderBy, limit int64) []*T {
+	total := 0
+	for _, partition := range partitions {
+		total += len(partition)
+	}
+	if limit > 0 && int64(total) > limit {
+		total = int(limit)
+	}
+	entities := make([]*T, 0, total)
+	heads := make([]int, len(partitions))
+	for len(entities) < total {
+		next := -1
+		for i, partition := range partitions {
+			if heads[i] == len(partition) {
+				continue
+			}
+			if next < 0 || len(orderBy) > 0 && compareKeys(partition[heads[i]].keys, partitions[next][heads[next]].keys, orderBy) < 0 {
+				next = i
+			}
+			if len(orderBy) == 0 {
+				break
+			}
+		}
+		entities = append(entities, partitions[next][heads[next]].entity)
+		heads[next]++
+	}
+	return entities
+}
+
+// sortKeys 复制文档中排序字段的值 缺失字段为空值
+func sortKeys(document bson.Raw, orderBy []*OrderBy) []bson.RawValue {
+	if len(orderBy) == 0 {
+		return nil
+	}
+	keys := make([]bson.RawValue, len(orderBy))
+	for i, order := range orderBy {
+		if value, err := document.LookupErr(strings.Split(order.Column, ".")...); err == nil {
+			keys[i] = bson.RawValue{Type: value.Type, Value: bytes.Clone(value.Value)}
+		}
+	}
+	return keys
+}
+
+// withoutPaths 移除文档中的指定字段 嵌套字段使用点号路径
+func withoutPaths(document bson.Raw, paths []string) (bson.Raw, error) {
+	elements, err := document.Elements()
+	if err != nil {
+		return nil, err
+	}
+	trimmed := make(bson.D, 0, len(elements))
+	for _, element := range elements {
+		key, value := element.Key(), element.Value()
+		var nested []string
+		removed := false
+		for _, path := range paths {
+			if path == key {
+				removed = true
+			} else if rest, ok := strings.CutPrefix(path, key+"."); ok {
+				nested = append(nested, rest)
+			}
+		}
+		if removed {
+			continue
+		}
+		if len(nested) > 0 && value.Type == bson.TypeEmbeddedDocument {
+			inner, err := withoutPaths(value.Document(), nested)
+			if err != nil {
+				return nil, err
+			}
+			trimmed = append(trimmed, bson.E{Key: key, Value: inner})
+			continue
+		}
+		trimmed = append(trimmed, bson.E{Key: key, Value: value})
+	}
+	return bson.Marshal(trimmed)
+}
+
+// compareKeys 按排序规则比较两组排序字段的值
+func compareKeys(left, right []bson.RawValue, orderBy []*OrderBy) int {
+	for i, order := range orderBy {
+		result := compareValues(left[i], right[i])
+		if order.Desc {
+			result = -result
+		}
+		if result != 0 {
+			return result
+		}
+	}
+	return 0
+}
+
+// compareValues 按 MongoDB 的 BSON 类型排序规则比较两个值 缺失字段视为 null
+func compareValues(left, right bson.RawValue) int {
+	leftRank, rightRank := typeRank(left.Type), typeRank(right.Type)
+	if leftRank != rightRank {
+		return cmp.Compare(leftRank, rightRank)
+	}
+	switch leftRank {
+	case 1:
+		return 0
+	case 2:
+		return cmp.Compare(numericValue(left), numericValue(right))
+	case 3:
+		return strings.Compare(stringValue(left), stringValue(right))
+	case 7:
+		return bytes.Compare(left.Value, right.Value)
+	case 8:
+		return cmp.Compare(boolRank(left.Boolean()), boolRank(right.Boolean()))
+	case 9:
+		return cmp.Compare(left.DateTime(), right.DateTime())
+	case 10:
+		leftT, leftI := left.Timestamp()
+		rightT, rightI := right.Timestamp()
+		if leftT != rightT {
+			return cmp.Compare(leftT, rightT)
+		}
+		return cmp.Compare(leftI, rightI)
+	default:
+		return bytes.Compare(left.Value, right.Value)
+	}
+}
+
+// typeRank BSON 类型在 MongoDB 排序中的顺序
+func typeRank(t bson.Type) int {
+	switch t {
+	case bson.TypeMinKey:
+		return 0
+	case 0, bson.TypeNull, bson.TypeUndefined:
+		return 1
+	case bson.TypeInt32, bson.TypeInt64, bson.TypeDouble, bson.TypeDecimal128:
+		return 2
+	case bson.TypeString, bson.TypeSymbol:
+		return 3
+	case bson.TypeEmbeddedDocument:
+		return 4
+	case bson.TypeArray:
+		return 5
+	case bson.TypeBinary:
+		return 6
+	case bson.TypeObjectID:
+		return 7
+	case bson.TypeBoolean:
+		return 8
+	case bson.TypeDateTime:
+		return 9
+	case bson.TypeTimestamp:
+		return 10
+	case bson.TypeRegex:
+		return 11
+	case bson.TypeMaxKey:
+		return 13
+	default:
+		return 12
+	}
+}
+
+func numericValue(value bson.RawValue) float64 {
+	switch value.Type {
+	case bson.TypeInt32:
+		return float64(value.Int32())
+	case bson.TypeInt64:
+		return float64(value.Int64())
+	case bson.TypeDouble:
+		return value.Double()
+	default:
+		number, err := strconv.ParseFloat(value.Decimal128().String(), 64)
+		if err != nil {
+			return math.NaN()
+		}
+		return number
+	}
+}
+
+func stringValue(value bson.RawValue) string {
+	if value.Type == bson.TypeSymbol {
+		return value.Symbol()
+	}
+	return value.StringValue()
+}
+
+func boolRank(value bool) int {
+	if value {
+		return 1
+	}
+	return 0
+}
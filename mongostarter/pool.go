@@ -0,0 +1,115 @@
+package mongostarter
+
+import (
+	"sort"
+	"sync"
+
+	"go.mongodb.org/mongo-driver/v2/event"
+)
+
+var mongoPool *poolTracker
+
+// PoolStats 单个服务端地址的连接池统计
+type PoolStats struct {
+	// 服务端地址
+	Address string `json:"address"`
+	// 已建立的连接数
+	Open int64 `json:"open"`
+	// 已借出的连接数
+	CheckedOut int64 `json:"checkedOut"`
+	// 空闲连接数
+	Idle int64 `json:"idle"`
+	// 借出连接失败次数
+	CheckoutFailures uint64 `json:"checkoutFailures"`
+}
+
+// PoolHooks 连接池事件回调 回调在驱动的事件线程中同步执行 不应阻塞
+type PoolHooks struct {
+	// 新连接建立
+	OnConnectionCreated func(evt *event.PoolEvent)
+	// 连接关闭 关闭原因见 evt.Reason
+	OnConnectionClosed func(evt *event.PoolEvent)
+	// 借出连接失败 如连接池耗尽等待超时 失败原因见 evt.Reason
+	OnCheckoutFailed func(evt *event.PoolEvent)
+}
+
+func (h *PoolHooks) monitor() *event.PoolMonitor {
+	if h == nil {
+		return nil
+	}
+	return &event.PoolMonitor{
+		Event: func(evt *event.PoolEvent) {
+			switch evt.Type {
+			case event.ConnectionCreated:
+				if h.OnConnectionCreated != nil {
+					h.OnConnectionCreated(evt)
+				}
+			case event.ConnectionClosed:
+				if h.OnConnectionClosed != nil {
+					h.OnConnectionClosed(evt)
+				}
+			case event.ConnectionCheckOutFailed:
+				if h.OnCheckoutFailed != nil {
+					h.OnCheckoutFailed(evt)
+				}
+			}
+		},
+	}
+}
+
+type poolTracker struct {
+	mu    sync.Mutex
+	pools map[string]*PoolStats
+}
+
+func newPoolTracker() *poolTracker {
+	return &poolTracker{pools: make(map[string]*PoolStats)}
+}
+
+func (t *poolTracker) pool(address string) *PoolStats {
+	stats, ok := t.pools[address]
+	if !ok {
+		stats = &PoolStats{Address: address}
+		t.pools[address] = stats
+	}
+	return stats
+}
+
+func (t *poolTracker) monitor() *event.PoolMonitor {
+	return &event.PoolMonitor{
+		Event: func(evt *event.PoolEvent) {
+			t.mu.Lock()
+			defer t.mu.Unlock()
+			switch evt.Type {
+			case event.ConnectionCreated:
+				t.pool(evt.Address).Open++
+			case event.ConnectionClosed:
+				t.pool(evt.Address).Open--
+			case event.ConnectionCheckedOut:
+				t.pool(evt.Address).CheckedOut++
+			case event.ConnectionCheckedIn:
+				t.pool(evt.Address).CheckedOut--
+			case event.ConnectionCheckOutFailed:
+				t.pool(evt.Address).CheckoutFailures++
+			case event.ConnectionPoolClosed:
+				delete(t.pools, evt.Address)
+			}
+		},
+	}
+}
+
+// snapshot 按地址排序返回当前连接池统计副本
+func (t *poolTracker) snapshot() []PoolStats {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	result := make([]PoolStats, 0, len(t.pools))
+	for _, stats := range t.pools {
+		item := *stats
+		item.Idle = max(item.Open-item.CheckedOut, 0)
+		result = append(result, item)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Address < result[j].Address
+	})
+	return result
+}
@@ -0,0 +1,166 @@
+package mongostarter
+
+import (
+	"context"
+	"reflect"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+// SelectAs 查询数据并解码为 R 投影由 R 的 bson 标签推导 嵌套结构体展开为点号路径
+// condition 可以是 *T 实体条件、bson.M 或其他过滤文档 nil 表示查询全部
+// excludeColumns 非空时改为排除指定字段的投影 不再按 R 推导
+func SelectAs[T Model, R any](mapper BaseMapper[T], condition any, orderBy []*OrderBy, result *[]*R, excludeColumns ...string) error {
+	filter := mapper.filter(condition)
+	opt := options.Find()
+	if len(excludeColumns) > 0 {
+		opt.SetProjection(exclusionProjection(excludeColumns))
+	} else if projection := projectionOf(reflect.TypeFor[R](), jsonStructTags()); projection != nil {
+		opt.SetProjection(projection)
+	}
+	if len(orderBy) > 0 {
+		setOrderBy(&opt, orderBy)
+	}
+	return mapper.execute("SelectAs", func(ctx context.Context, coll *mongo.Collection) (int64, error) {
+		cursor, err := coll.Find(ctx, filter, opt)
+		err = checkMultipleResult(ctx, cursor, err, result)
+		return multipleCount(result, err)
+	})
+}
+
+// SelectPageAs 分页查询数据并解码为 R 返回总数统计信息 未指定 SpecifyColumns 与 ExcludeColumns 时投影由 R 的 bson 标签推导
+// condition 可以是 *T 实体条件、bson.M 或其他过滤文档 nil 表示查询全部
+func SelectPageAs[T Model, R any](mapper BaseMapper[T], condition any, query PageQuery, result *[]*R) (count PageCount, err error) {
+	if err = query.validate(); err != nil {
+		return PageCount{}, err
+	}
+	filter := mapper.filter(condition)
+	opt, err := pageProjection(query)
+	if err != nil {
+		return PageCount{}, err
+	}
+	if opt == nil {
+		opt = options.Find()
+		if projection := projectionOf(reflect.TypeFor[R](), jsonStructTags()); projection != nil {
+			opt.SetProjection(projection)
+		}
+	}
+	if len(query.OrderBy) > 0 {
+		setOrderBy(&opt, query.OrderBy)
+	}
+	setPage(&opt, query.PageNumber, query.PageSize)
+	err = mapper.execute("SelectPageAs", func(ctx context.Context, coll *mongo.Collection) (int64, error) {
+		count, err = countPage(ctx, coll, filter, query)
+		if err != nil {
+			return 0, err
+		}
+		cursor, err := coll.Find(ctx, filter, opt)
+		err = checkMultipleResult(ctx, cursor, err, result)
+		return multipleCount(result, err)
+	})
+	if err != nil {
+		return PageCount{}, err
+	}
+	return count, nil
+}
+
+// filter 转换任意风格的条件 *T 按实体条件模式转换 nil 表示查询全部
+func (b BaseMapper[T]) filter(condition any) any {
+	switch c := condition.(type) {
+	case nil:
+		return bson.D{}
+	case *T:
+		if c == nil {
+			return bson.D{}
+		}
+		return b.condition(c)
+	case bson.M:
+		if c == nil {
+			return bson.D{}
+		}
+	}
+	return condition
+}
+
+// pageProjection 按 PageQuery 的 SpecifyColumns 或 ExcludeColumns 生成投影 均未指定时返回 nil
+func pageProjection(query PageQuery) (*options.FindOptionsBuilder, error) {
+	if len(query.SpecifyColumns) > 0 && len(query.ExcludeColumns) > 0 {
+		return nil, ErrInvalidProjection
+	}
+	if len(query.ExcludeColumns) > 0 {
+		return options.Find().SetProjection(exclusionProjection(query.ExcludeColumns)), nil
+	}
+	return specifyColumnsOpt(query.SpecifyColumns...), nil
+}
+
+// exclusionProjection 排除指定字段的投影
+func exclusionProjection(columns []string) bson.D {
+	projection := make(bson.D, 0, len(columns))
+	for _, column := range columns {
+		projection = append(projection, bson.E{Key: column, Value: 0})
+	}
+	return projection
+}
+
+// projectionOf 由结构体字段推导投影 R 不包含 _id 字段时排除 _id 无法推导时返回 nil 表示返回完整文档
+func projectionOf(t reflect.Type, jsonTags bool) bson.D {
+	t = structType(t)
+	if t == nil {
+		return nil
+	}
+	paths, ok := projectionPaths(t, "", jsonTags, map[reflect.Type]bool{})
+	if !ok || len(paths) == 0 {
+		return nil
+	}
+	projection := make(bson.D, 0, len(paths)+1)
+	hasID := false
+	for _, path := range paths {
+		hasID = hasID || path == "_id"
+		projection = append(projection, bson.E{Key: path, Value: 1})
+	}
+	if !hasID {
+		projection = append(projection, bson.E{Key: "_id", Value: 0})
+	}
+	return projection
+}
+
+// projectionPaths 收集结构体字段路径 结构体及其切片展开为子路径 inline map 接收任意字段时 ok 为 false
+func projectionPaths(t reflect.Type, prefix string, jsonTags bool, visiting map[reflect.Type]bool) (paths []string, ok bool) {
+	visiting[t] = true
+	defer delete(visiting, t)
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		info, ok := parseStructField(field, jsonTags)
+		if !ok {
+			continue
+		}
+		if info.inline {
+			inner := structType(field.Type)
+			if inner == nil || visiting[inner] {
+				return nil, false
+			}
+			nested, ok := projectionPaths(inner, prefix, jsonTags, visiting)
+			if !ok {
+				return nil, false
+			}
+			paths = append(paths, nested...)
+			continue
+		}
+		path := joinPath(prefix, info.name)
+		elem := field.Type
+		for elem.Kind() == reflect.Ptr || elem.Kind() == reflect.Slice || elem.Kind() == reflect.Array {
+			elem = elem.Elem()
+		}
+		if nestedStruct(elem) && !visiting[elem] {
+			// 子文档无法推导时整体投影
+			if nested, ok := projectionPaths(elem, path, jsonTags, visiting); ok && len(nested) > 0 {
+				paths = append(paths, nested...)
+				continue
+			}
+		}
+		paths = append(paths, path)
+	}
+	return paths, true
+}
@@ -0,0 +1,238 @@
+package mongostarter
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+// CollectionOptions 集合创建选项 仅在集合不存在时用于创建 已存在的集合只做比对
+type CollectionOptions struct {
+	// 固定集合 需要同时设置 SizeInBytes
+	Capped bool
+	// 固定集合的最大字节数 服务端会向上取整到 256 的倍数
+	SizeInBytes int64
+	// 固定集合的最大文档数
+	MaxDocuments int64
+	// 时序集合配置 非 nil 时创建时序集合
+	TimeSeries *TimeSeriesOptions
+	// 聚簇集合 按 _id 聚簇存储
+	Clustered bool
+	// 文档过期时间 仅用于时序集合与聚簇集合 精度为秒
+	ExpireAfter time.Duration
+	// 集合默认排序规则
+	Collation *options.Collation
+}
+
+// TimeSeriesOptions 时序集合配置
+type TimeSeriesOptions struct {
+	// 时间字段
+	TimeField string
+	// 元数据字段
+	MetaField string
+	// 时间粒度 seconds、minutes 或 hours
+	Granularity string
+}
+
+// ProvisionedModel 模型实现该接口声明集合创建选项
+type ProvisionedModel interface {
+	Model
+	CollectionOptions() CollectionOptions
+}
+
+// ProvisionConfig 集合预创建配置
+type ProvisionConfig struct {
+	// 需要预创建的模型 未实现 ProvisionedModel 的模型创建为普通集合
+	Models []Model
+	// 只报告缺失与不一致的集合 不创建
+	DryRun bool
+}
+
+// ProvisionReport 单个集合的预创建结果
+type ProvisionReport struct {
+	// 集合名称
+	Collection string
+	// 集合不存在 DryRun 时表示需要创建
+	Missing bool
+	// 已创建集合
+	Created bool
+	// 已存在集合与声明不一致的选项 已存在的集合不会被修改
+	Mismatches []string
+}
+
+// ProvisionCollections 按模型声明创建缺失的集合 已存在的集合与声明比对并报告不一致的选项
+func ProvisionCollections(ctx context.Context, config ProvisionConfig) ([]ProvisionReport, error) {
+	database := RawDatabase()
+	if database == nil {
+		return nil, ErrMongoStarterNotStarted
+	}
+	return provisionCollections(ctx, database, config, currentNaming())
+}
+
+func provisionCollections(ctx context.Context, database *mongo.Database, config ProvisionConfig, naming *NamingStrategy) ([]ProvisionReport, error) {
+	reports := make([]ProvisionReport, 0, len(config.Models))
+	for _, model := range config.Models {
+		var declared CollectionOptions
+		if provisioned, ok := model.(ProvisionedModel); ok {
+			declared = provisioned.CollectionOptions()
+		}
+		report := ProvisionReport{Collection: modelCollectionName(model, naming)}
+		if err := declared.validate(); err != nil {
+			return reports, fmt.Errorf("%s: %w", report.Collection, err)
+		}
+		db := modelDatabase(database, model)
+		specs, err := db.ListCollectionSpecifications(ctx, bson.M{"name": report.Collection})
+		if err != nil {
+			return reports, err
+		}
+		if len(specs) == 0 {
+			report.Missing = true
+			if !config.DryRun {
+				if err = db.CreateCollection(ctx, report.Collection, declared.createOptions()); err != nil {
+					return reports, err
+				}
+				report.Created = true
+			}
+			reports = append(reports, report)
+			continue
+		}
+		if report.Mismatches, err = declared.compare(specs[0]); err != nil {
+			return reports, err
+		}
+		reports = append(reports, report)
+	}
+	return reports, nil
+}
+
+func (o CollectionOptions) validate() error {
+	switch {
+	case o.Capped && o.SizeInBytes <= 0:
+		return fmt.Errorf("%w: capped collection requires SizeInBytes", ErrInvalidCollectionOptions)
+	case !o.Capped && (o.SizeInBytes > 0 || o.MaxDocuments > 0):
+		return fmt.Errorf("%w: SizeInBytes and MaxDocuments require Capped", ErrInvalidCollectionOptions)
+	case o.Capped && (o.TimeSeries != nil || o.Clustered):
+		return fmt.Errorf("%w: capped collection cannot be time-series or clustered", ErrInvalidCollectionOptions)
+	case o.TimeSeries != nil && o.Clustered:
+		return fmt.Errorf("%w: time-series collection cannot be clustered", ErrInvalidCollectionOptions)
+	case o.TimeSeries != nil && o.TimeSeries.TimeField == "":
+		return fmt.Errorf("%w: time-series collection requires TimeField", ErrInvalidCollectionOptions)
+	case o.ExpireAfter > 0 && o.TimeSeries == nil && !o.Clustered:
+		return fmt.Errorf("%w: ExpireAfter requires a time-series or clustered collection", ErrInvalidCollectionOptions)
+	}
+	return nil
+}
+
+func (o CollectionOptions) createOptions() *options.CreateCollectionOptionsBuilder {
+	opts := options.CreateCollection()
+	if o.Capped {
+		opts.SetCapped(true).SetSizeInBytes(o.SizeInBytes)
+		if o.MaxDocuments > 0 {
+			opts.SetMaxDocuments(o.MaxDocuments)
+		}
+	}
+	if o.TimeSeries != nil {
+		timeSeries := options.TimeSeries().SetTimeField(o.TimeSeries.TimeField)
+		if o.TimeSeries.MetaField != "" {
+			timeSeries.SetMetaField(o.TimeSeries.MetaField)
+		}
+		if o.TimeSeries.Granularity != "" {
+			timeSeries.SetGranularity(o.TimeSeries.Granularity)
+		}
+		opts.SetTimeSeriesOptions(timeSeries)
+	}
+	if o.Clustered {
+		opts.SetClusteredIndex(bson.D{
+			{Key: "key", Value: bson.D{{Key: "_id", Value: 1}}},
+			{Key: "unique", Value: true},
+		})
+	}
+	if o.ExpireAfter > 0 {
+		opts.SetExpireAfterSeconds(int64(o.ExpireAfter / time.Second))
+	}
+	if o.Collation != nil {
+		opts.SetCollation(o.Collation)
+	}
+	return opts
+}
+
+// collectionSpec listCollections 返回的集合选项
+type collectionSpec struct {
+	Capped     bool  `bson:"capped"`
+	Size       int64 `bson:"size"`
+	Max        int64 `bson:"max"`
+	TimeSeries *struct {
+		TimeField   string `bson:"timeField"`
+		MetaField   string `bson:"metaField"`
+		Granularity string `bson:"granularity"`
+	} `bson:"timeseries"`
+	ClusteredIndex     bson.Raw `bson:"clusteredIndex"`
+	ExpireAfterSeconds *int64   `bson:"expireAfterSeconds"`
+	Collation          bson.Raw `bson:"collation"`
+}
+
+// compare 比较声明与已存在集合的选项 返回不一致的选项
+func (o CollectionOptions) compare(spec mongo.CollectionSpecification) ([]string, error) {
+	var live collectionSpec
+	if spec.Options != nil {
+		if err := bson.Unmarshal(spec.Options, &live); err != nil {
+			return nil, err
+		}
+	}
+	var mismatches []string
+	mismatch := func(format string, args ...any) {
+		mismatches = append(mismatches, fmt.Sprintf(format, args...))
+	}
+	if o.Capped != live.Capped {
+		mismatch("capped: live %t, declared %t", live.Capped, o.Capped)
+	} else if o.Capped {
+		if live.Size < o.SizeInBytes || live.Size >= o.SizeInBytes+256 {
+			mismatch("size: live %d, declared %d", live.Size, o.SizeInBytes)
+		}
+		if live.Max != o.MaxDocuments {
+			mismatch("max: live %d, declared %d", live.Max, o.MaxDocuments)
+		}
+	}
+	switch {
+	case o.TimeSeries != nil && live.TimeSeries == nil:
+		mismatch("timeseries: live none, declared timeField %s", o.TimeSeries.TimeField)
+	case o.TimeSeries == nil && live.TimeSeries != nil:
+		mismatch("timeseries: live timeField %s, declared none", live.TimeSeries.TimeField)
+	case o.TimeSeries != nil:
+		if live.TimeSeries.TimeField != o.TimeSeries.TimeField {
+			mismatch("timeseries.timeField: live %s, declared %s", live.TimeSeries.TimeField, o.TimeSeries.TimeField)
+		}
+		if live.TimeSeries.MetaField != o.TimeSeries.MetaField {
+			mismatch("timeseries.metaField: live %s, declared %s", live.TimeSeries.MetaField, o.TimeSeries.MetaField)
+		}
+		if o.TimeSeries.Granularity != "" && live.TimeSeries.Granularity != o.TimeSeries.Granularity {
+			mismatch("timeseries.granularity: live %s, declared %s", live.TimeSeries.Granularity, o.TimeSeries.Granularity)
+		}
+	}
+	if clustered := live.ClusteredIndex != nil; clustered != o.Clustered {
+		mismatch("clustered: live %t, declared %t", clustered, o.Clustered)
+	}
+	declaredExpire, liveExpire := int64(-1), int64(-1)
+	if o.ExpireAfter > 0 {
+		declaredExpire = int64(o.ExpireAfter / time.Second)
+	}
+	if live.ExpireAfterSeconds != nil {
+		liveExpire = *live.ExpireAfterSeconds
+	}
+	if declaredExpire != liveExpire {
+		mismatch("expireAfterSeconds: live %d, declared %d", liveExpire, declaredExpire)
+	}
+	switch {
+	case o.Collation == nil && live.Collation != nil:
+		mismatch("collation: live %s, declared none", live.Collation.String())
+	case o.Collation != nil && live.Collation == nil:
+		mismatch("collation: live none, declared locale %s", o.Collation.Locale)
+	case o.Collation != nil && !documentEqual(collationDocument(o.Collation), live.Collation, true):
+		// 服务端会补全排序规则的默认值 只比较声明的部分
+		mismatch("collation: live %s, declared locale %s", live.Collation.String(), o.Collation.Locale)
+	}
+	return mismatches, nil
+}
@@ -17,12 +17,12 @@ func checkSingleResult(singleResult *mongo.SingleResult, result any) error {
 }
 
 // checkMultipleResult 检查多条查询结果
-func checkMultipleResult(cursor *mongo.Cursor, err error, result any) error {
+func checkMultipleResult(ctx context.Context, cursor *mongo.Cursor, err error, result any) error {
 	if err != nil {
 		return err
 	}
-	defer cursor.Close(context.Background())
-	return cursor.All(context.Background(), result)
+	defer cursor.Close(ctx)
+	return cursor.All(ctx, result)
 }
 
 // checkSingleInsertResult 检查单条插入结果
@@ -0,0 +1,369 @@
+package mongostarter
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+var (
+	timestampType      = reflect.TypeOf(Timestamp{})
+	objectIDType       = reflect.TypeOf(bson.ObjectID{})
+	decimal128Type     = reflect.TypeOf(bson.Decimal128{})
+	valueMarshalerType = reflect.TypeOf((*bson.ValueMarshaler)(nil)).Elem()
+)
+
+// ValidatorConfig 集合校验器同步配置
+type ValidatorConfig struct {
+	// 需要同步校验器的模型
+	Models []Model
+	// 校验级别 strict、moderate 或 off 默认 strict
+	Level string
+	// 校验失败的处理方式 error 或 warn 默认 error
+	Action string
+	// 只报告差异 不创建集合也不修改校验器
+	DryRun bool
+}
+
+// ValidatorReport 单个集合的校验器同步结果
+type ValidatorReport struct {
+	// 集合名称
+	Collection string
+	// 集合不存在 已携带校验器创建
+	Created bool
+	// 已通过 collMod 更新校验器
+	Updated bool
+	// 线上校验器与生成结果的差异 为空表示一致
+	Differences []string
+}
+
+// JSONSchema 根据模型的 bson 标签生成 $jsonSchema
+// 非指针且未设置 omitempty 的字段为必填 可通过 schema 标签调整:
+// schema:"required" 强制必填 schema:"optional" 取消必填 schema:"enum=a|b|c" 限定取值 schema:"-" 不校验该字段
+func JSONSchema(model Model) (bson.M, error) {
+	return jsonSchema(reflect.TypeOf(model), jsonStructTags())
+}
+
+func jsonSchema(t reflect.Type, jsonTags bool) (bson.M, error) {
+	t = structType(t)
+	if t == nil {
+		return nil, fmt.Errorf("%w: model must be a struct", ErrInvalidSchema)
+	}
+	builder := &schemaBuilder{jsonTags: jsonTags, visiting: make(map[reflect.Type]bool)}
+	return builder.object(t, true)
+}
+
+type schemaBuilder struct {
+	jsonTags bool
+	visiting map[reflect.Type]bool
+}
+
+// object 生成结构体的 object 校验 root 为 true 时不校验 _id
+func (b *schemaBuilder) object(t reflect.Type, root bool) (bson.M, error) {
+	schema := bson.M{"bsonType": "object"}
+	if b.visiting[t] {
+		return schema, nil
+	}
+	b.visiting[t] = true
+	defer delete(b.visiting, t)
+	properties := bson.M{}
+	var required []string
+	if err := b.fields(t, root, properties, &required); err != nil {
+		return nil, err
+	}
+	if len(properties) > 0 {
+		schema["properties"] = properties
+	}
+	if len(required) > 0 {
+		sort.Strings(required)
+		schema["required"] = required
+	}
+	return schema, nil
+}
+
+func (b *schemaBuilder) fields(t reflect.Type, root bool, properties bson.M, required *[]string) error {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		info, ok := parseStructField(field, b.jsonTags)
+		if !ok {
+			continue
+		}
+		tag := field.Tag.Get("schema")
+		if tag == "-" || (root && info.name == "_id") {
+			continue
+		}
+		if info.inline {
+			if nested := structType(field.Type); nested != nil {
+				if err := b.fields(nested, root, properties, required); err != nil {
+					return err
+				}
+			}
+			continue
+		}
+		property, nullable, err := b.value(field.Type)
+		if err != nil {
+			return fmt.Errorf("field %s: %w", field.Name, err)
+		}
+		isRequired := !info.omitEmpty && !nullable
+		for _, option := range strings.Split(tag, ",") {
+			switch {
+			case option == "":
+			case option == "required":
+				isRequired = true
+			case option == "optional":
+				isRequired = false
+			case strings.HasPrefix(option, "enum="):
+				values, err := enumValues(field.Type, strings.Split(strings.TrimPrefix(option, "enum="), "|"))
+				if err != nil {
+					return fmt.Errorf("field %s: %w", field.Name, err)
+				}
+				property["enum"] = values
+			default:
+				return fmt.Errorf("%w: field %s: unknown option %q", ErrInvalidSchema, field.Name, option)
+			}
+		}
+		properties[info.name] = property
+		if isRequired {
+			*required = append(*required, info.name)
+		}
+	}
+	return nil
+}
+
+// value 生成字段值的校验 nullable 表示零值会编码为 null
+func (b *schemaBuilder) value(t reflect.Type) (schema bson.M, nullable bool, err error) {
+	if t.Kind() == reflect.Ptr {
+		schema, _, err = b.value(t.Elem())
+		return withNull(schema), true, err
+	}
+	switch t {
+	case timeType:
+		return bson.M{"bsonType": "date"}, false, nil
+	case timestampType:
+		return bson.M{"bsonType": bson.A{"date", "null"}}, true, nil
+	case objectIDType:
+		return bson.M{"bsonType": "objectId"}, false, nil
+	case decimal128Type:
+		return bson.M{"bsonType": "decimal"}, false, nil
+	}
+	// 自定义编码的类型无法推断 BSON 类型
+	if t.Implements(valueMarshalerType) || reflect.PointerTo(t).Implements(valueMarshalerType) {
+		return bson.M{}, true, nil
+	}
+	switch t.Kind() {
+	case reflect.String:
+		return bson.M{"bsonType": "string"}, false, nil
+	case reflect.Bool:
+		return bson.M{"bsonType": "bool"}, false, nil
+	case reflect.Int8, reflect.Int16, reflect.Int32, reflect.Uint8, reflect.Uint16:
+		return bson.M{"bsonType": "int"}, false, nil
+	case reflect.Int, reflect.Int64, reflect.Uint, reflect.Uint32, reflect.Uint64:
+		return bson.M{"bsonType": bson.A{"int", "long"}}, false, nil
+	case reflect.Float32, reflect.Float64:
+		return bson.M{"bsonType": "double"}, false, nil
+	case reflect.Slice:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return bson.M{"bsonType": bson.A{"binData", "null"}}, true, nil
+		}
+		items, _, err := b.value(t.Elem())
+		if err != nil {
+			return nil, false, err
+		}
+		return withNull(bson.M{"bsonType": "array", "items": items}), true, nil
+	case reflect.Array:
+		items, _, err := b.value(t.Elem())
+		if err != nil {
+			return nil, false, err
+		}
+		return bson.M{"bsonType": "array", "items": items}, false, nil
+	case reflect.Map:
+		return bson.M{"bsonType": bson.A{"object", "null"}}, true, nil
+	case reflect.Struct:
+		schema, err := b.object(t, false)
+		return schema, false, err
+	case reflect.Interface:
+		return bson.M{}, true, nil
+	}
+	return nil, false, fmt.Errorf("%w: unsupported type %s", ErrInvalidSchema, t)
+}
+
+// withNull 在 bsonType 中追加 null
+func withNull(schema bson.M) bson.M {
+	switch value := schema["bsonType"].(type) {
+	case string:
+		schema["bsonType"] = bson.A{value, "null"}
+	case bson.A:
+		schema["bsonType"] = append(value, "null")
+	}
+	return schema
+}
+
+// enumValues 按字段类型转换枚举值
+func enumValues(t reflect.Type, values []string) (bson.A, error) {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	result := make(bson.A, 0, len(values))
+	for _, value := range values {
+		switch t.Kind() {
+		case reflect.String:
+			result = append(result, value)
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+			reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			number, err := strconv.ParseInt(value, 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("%w: invalid enum value %q", ErrInvalidSchema, value)
+			}
+			result = append(result, number)
+		case reflect.Float32, reflect.Float64:
+			number, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				return nil, fmt.Errorf("%w: invalid enum value %q", ErrInvalidSchema, value)
+			}
+			result = append(result, number)
+		default:
+			return nil, fmt.Errorf("%w: enum is not supported for %s", ErrInvalidSchema, t)
+		}
+	}
+	return result, nil
+}
+
+// SyncValidators 为模型生成 $jsonSchema 校验器 集合不存在时携带校验器创建 已存在且不一致时通过 collMod 更新
+func SyncValidators(ctx context.Context, config ValidatorConfig) ([]ValidatorReport, error) {
+	database := RawDatabase()
+	if database == nil {
+		return nil, ErrMongoStarterNotStarted
+	}
+	return syncValidators(ctx, database, config, jsonStructTags(), currentNaming())
+}
+
+func syncValidators(ctx context.Context, database *mongo.Database, config ValidatorConfig, jsonTags bool, naming *NamingStrategy) ([]ValidatorReport, error) {
+	level, action := config.Level, config.Action
+	if level == "" {
+		level = "strict"
+	}
+	if action == "" {
+		action = "error"
+	}
+	reports := make([]ValidatorReport, 0, len(config.Models))
+	for _, model := range config.Models {
+		collection := modelCollectionName(model, naming)
+		schema, err := jsonSchema(reflect.TypeOf(model), jsonTags)
+		if err != nil {
+			return reports, fmt.Errorf("%s: %w", collection, err)
+		}
+		validator := bson.M{"$jsonSchema": schema}
+		db := modelDatabase(database, model)
+		report := ValidatorReport{Collection: collection}
+		specs, err := db.ListCollectionSpecifications(ctx, bson.M{"name": report.Collection})
+		if err != nil {
+			return reports, err
+		}
+		if len(specs) == 0 {
+			report.Differences = []string{"collection does not exist"}
+			if !config.DryRun {
+				err = db.CreateCollection(ctx, report.Collection, options.CreateCollection().
+					SetValidator(validator).SetValidationLevel(level).SetValidationAction(action))
+				if err != nil {
+					return reports, err
+				}
+				report.Created = true
+			}
+			reports = append(reports, report)
+			continue
+		}
+		var live struct {
+			Validator        bson.M `bson:"validator"`
+			ValidationLevel  string `bson:"validationLevel"`
+			ValidationAction string `bson:"validationAction"`
+		}
+		if specs[0].Options != nil {
+			if err = bson.Unmarshal(specs[0].Options, &live); err != nil {
+				return reports, err
+			}
+		}
+		if live.ValidationLevel == "" {
+			live.ValidationLevel = "strict"
+		}
+		if live.ValidationAction == "" {
+			live.ValidationAction = "error"
+		}
+		if live.ValidationLevel != level {
+			report.Differences = append(report.Differences, fmt.Sprintf("validationLevel: live %s, declared %s", live.ValidationLevel, level))
+		}
+		if live.ValidationAction != action {
+			report.Differences = append(report.Differences, fmt.Sprintf("validationAction: live %s, declared %s", live.ValidationAction, action))
+		}
+		differences, err := diffDocuments(live.Validator, validator)
+		if err != nil {
+			return reports, err
+		}
+		report.Differences = append(report.Differences, differences...)
+		if len(report.Differences) > 0 && !config.DryRun {
+			err = db.RunCommand(ctx, bson.D{
+				{Key: "collMod", Value: report.Collection},
+				{Key: "validator", Value: validator},
+				{Key: "validationLevel", Value: level},
+				{Key: "validationAction", Value: action},
+			}).Err()
+			if err != nil {
+				return reports, err
+			}
+			report.Updated = true
+		}
+		reports = append(reports, report)
+	}
+	return reports, nil
+}
+
+// diffDocuments 以宽松扩展 JSON 比较两个文档 返回按路径排序的差异
+func diffDocuments(live, declared any) ([]string, error) {
+	var left, right map[string]any
+	var err error
+	if live != nil {
+		if left, err = normalizeDocument(live); err != nil {
+			return nil, err
+		}
+	}
+	if right, err = normalizeDocument(declared); err != nil {
+		return nil, err
+	}
+	var differences []string
+	diffValues("", left, right, &differences)
+	sort.Strings(differences)
+	return differences, nil
+}
+
+func diffValues(path string, live, declared any, differences *[]string) {
+	liveMap, liveIsMap := live.(map[string]any)
+	declaredMap, declaredIsMap := declared.(map[string]any)
+	if liveIsMap && declaredIsMap {
+		for key, value := range declaredMap {
+			diffValues(joinPath(path, key), liveMap[key], value, differences)
+		}
+		for key, value := range liveMap {
+			if _, ok := declaredMap[key]; !ok {
+				diffValues(joinPath(path, key), value, nil, differences)
+			}
+		}
+		return
+	}
+	if reflect.DeepEqual(live, declared) {
+		return
+	}
+	switch {
+	case live == nil:
+		*differences = append(*differences, fmt.Sprintf("%s: missing, declared %v", path, declared))
+	case declared == nil:
+		*differences = append(*differences, fmt.Sprintf("%s: live %v, not declared", path, live))
+	default:
+		*differences = append(*differences, fmt.Sprintf("%s: live %v, declared %v", path, live, declared))
+	}
+}
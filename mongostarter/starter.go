@@ -4,8 +4,6 @@ import (
 	"context"
 	"errors"
 	"fmt"
-	"net/url"
-	"strings"
 	"sync"
 	"time"
 
@@ -14,27 +12,93 @@ import (
 	"go.mongodb.org/mongo-driver/v2/event"
 	"go.mongodb.org/mongo-driver/v2/mongo"
 	"go.mongodb.org/mongo-driver/v2/mongo/options"
-	"go.mongodb.org/mongo-driver/v2/mongo/readpref"
+	"go.opentelemetry.io/otel/trace"
 )
 
 var (
 	mongoClient     *mongo.Client
 	defaultDatabase string
 	mongoLock       sync.RWMutex
+	// 停止中 拒绝新的 Mapper 操作
+	mongoStopping bool
+	// 启动中 连接与启动任务在 mongoLock 之外执行 期间拒绝重复启动
+	mongoStarting bool
 )
 
 type MongoConfig struct {
 
 	// 工作数据库
 	Database string
-	// Mongo 链接地址串 如果设置了则忽略上面的配置
+	// Mongo 链接地址串 与下方结构化连接字段同时设置时 结构化字段的非零值优先
 	MongoURI string
+	// 服务端地址列表 如 127.0.0.1:27017 设置后替换 MongoURI 中的地址
+	Hosts []string
+	// 认证用户名
+	Username string
+	// 认证密码 不会出现在日志与错误信息中
+	Password string
+	// 凭据提供函数 设置后启动与轮换时由其提供用户名与密码 覆盖 Username 与 Password
+	CredentialProvider CredentialProvider
+	// 定时轮换凭据的间隔 为 0 时仅在认证失败时轮换
+	CredentialRefreshInterval time.Duration
+	// 认证机制 如 SCRAM-SHA-256、MONGODB-X509
+	AuthMechanism string
+	// 认证数据库
+	AuthSource string
+	// 副本集名称
+	ReplicaSet string
+	// 是否直连单个节点 nil 时使用 MongoURI 或驱动默认值
+	DirectConnection *bool
+	// TLS 配置 非 nil 时启用 TLS
+	TLS *TLSConfig
+	// 建立连接超时时间
+	ConnectTimeout time.Duration
+	// 单次操作的客户端超时时间 驱动 v2 不再支持 socketTimeoutMS 由该超时替代
+	Timeout time.Duration
+	// 每个服务端的最大连接数
+	MaxPoolSize uint64
+	// 每个服务端保持的最小连接数
+	MinPoolSize uint64
+	// 每个服务端同时建立中的最大连接数
+	MaxConnecting uint64
+	// 连接最大空闲时间 超过后关闭
+	MaxConnIdleTime time.Duration
+	// 服务端选择超时时间
+	ServerSelectionTimeout time.Duration
+	// 服务端心跳检测间隔 不能小于 500ms
+	HeartbeatInterval time.Duration
+	// 连接池事件回调
+	PoolHooks *PoolHooks
+	// 连接生命周期与拓扑变化回调
+	TopologyHooks *TopologyHooks
+	// 启动时预先建立 MinPoolSize 个连接后再返回
+	WarmUpPool bool
 	// 设置默认的 BSON 选项
 	BSONOptions *options.BSONOptions
 	// 开启详细日志
 	EnableLogger bool
 	// 网络压缩算法
 	Compressors []string
+	// 指标采集配置 非 nil 时启用命令与连接池指标
+	Metrics *MetricsConfig
+	// 链路追踪配置 非 nil 时为 Mapper 方法与驱动命令创建 Span
+	Tracing *TracingConfig
+	// 启动时连通性检查与重试策略
+	Startup StartupConfig
+	// 实体条件转换方式 默认 ConditionStruct 直接编码实体
+	ConditionMode ConditionMode
+	// 实体更新转换方式 默认 UpdateStruct 直接使用实体作为 $set 内容
+	UpdateMode UpdateMode
+	// 部分更新与字段掩码中值为 null 的 Optional 字段使用 $unset 删除 默认写入 null
+	UnsetNullOptionals bool
+	// 全局集合命名策略 非 nil 时转换全部集合名称
+	Naming *NamingStrategy
+	// 启动时按模型声明创建缺失的集合 在校验器与索引同步之前执行 失败时启动失败
+	Collections *ProvisionConfig
+	// 启动时同步模型声明的索引 非 nil 时在连通后执行 失败时启动失败
+	Indexes *IndexSyncConfig
+	// 启动时同步模型生成的集合校验器 在索引同步之前执行 失败时启动失败
+	Validators *ValidatorConfig
 
 	InitFunc func(instance *mongo.Client)
 }
@@ -78,81 +142,273 @@ func (m *MongoStarter) Setting() *parent.Setting {
 		})
 }
 
+// Start 连接、Ping 重试、预热与启动任务均不持有 mongoLock 仅在发布客户端时加锁
 func (m *MongoStarter) Start() (any, error) {
 	mongoLock.Lock()
-	defer mongoLock.Unlock()
-	if mongoClient != nil {
+	if mongoClient != nil || mongoStarting {
+		mongoLock.Unlock()
 		return nil, ErrMongoStarterAlreadyStarted
 	}
+	mongoStarting = true
+	mongoLock.Unlock()
+	defer func() {
+		mongoLock.Lock()
+		mongoStarting = false
+		mongoLock.Unlock()
+	}()
 
 	config := m.getConfig()
-	if strings.TrimSpace(config.MongoURI) == "" {
-		return nil, ErrMongoURIRequired
+	startup := config.Startup.withDefaults()
+	monitors := &clientMonitors{topology: newTopologyNotifier(config.TopologyHooks)}
+	if config.Metrics != nil {
+		monitors.metrics = newMetricsRegistry(config.Metrics)
 	}
-	database := config.Database
-	clientOptions := options.Client().ApplyURI(config.MongoURI)
-	if config.EnableLogger {
-		monitor := &event.CommandMonitor{
-			Started: func(ctx context.Context, evt *event.CommandStartedEvent) {
-				logger.Logrus().WithField("database", evt.DatabaseName).Traceln(evt.CommandName)
-			},
+	if config.Tracing != nil {
+		monitors.tracer = newTracer(config.Tracing)
+	}
+	var credential *Credential
+	if config.CredentialProvider != nil {
+		monitors.rotator = newCredentialRotator(config, startup, monitors)
+		initial, err := monitors.rotator.fetch(context.Background())
+		if err != nil {
+			monitors.topology.close()
+			return nil, err
 		}
-		clientOptions.SetMonitor(monitor)
+		credential = &initial
 	}
-	if config.Compressors != nil {
-		clientOptions.SetCompressors(config.Compressors)
-	} else {
-		clientOptions.SetCompressors([]string{"zstd", "zlib", "snappy"})
+	connectConfig := config.withCredential(credential)
+	state, database, err := dial(connectConfig, monitors)
+	if err != nil {
+		monitors.topology.close()
+		return nil, err
 	}
-	if config.BSONOptions != nil {
-		clientOptions.SetBSONOptions(config.BSONOptions)
+	jsonTags := config.BSONOptions != nil && config.BSONOptions.UseJSONStructTags
+	var naming *NamingStrategy
+	if config.Naming != nil {
+		strategy := *config.Naming
+		naming = &strategy
 	}
-	if database == "" {
-		// 从 URI 的路径部分提取数据库名称（如果有）
-		parsedURI, err := url.Parse(clientOptions.GetURI())
-		if err != nil {
-			return nil, fmt.Errorf("%w: %v", ErrInvalidMongoURI, err)
+	ready := func(ctx context.Context) error {
+		db := state.client.Database(database)
+		if config.Collections != nil {
+			if _, err := provisionCollections(ctx, db, *config.Collections, naming); err != nil {
+				return err
+			}
+		}
+		if config.Validators != nil {
+			if _, err := syncValidators(ctx, db, *config.Validators, jsonTags, naming); err != nil {
+				return err
+			}
+		}
+		if config.Indexes != nil {
+			if _, err := syncIndexes(ctx, db, *config.Indexes, jsonTags, naming); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	var cancel context.CancelFunc
+	if startup.Degraded {
+		cancel = startup.connectInBackground(state.client, state.pool, state.warmUp, ready, connectConfig)
+	} else {
+		if err = startup.pingWithRetry(context.Background(), state.client, startup.MaxAttempts, connectConfig); err == nil {
+			err = startup.warmUp(context.Background(), state.client, state.pool, state.warmUp)
 		}
-		// 获取路径部分并去掉前导的 '/'
-		uriDatabase := strings.TrimPrefix(parsedURI.Path, "/")
-		if uriDatabase != "" {
-			database = uriDatabase
+		if err == nil {
+			err = ready(context.Background())
 		}
+		if err != nil {
+			_ = state.client.Disconnect(context.Background())
+			monitors.topology.close()
+			return nil, connectConfig.redactError(err)
+		}
+	}
+
+	mongoLock.Lock()
+	defer mongoLock.Unlock()
+	publish(state)
+	startupCancel = cancel
+	defaultDatabase = database
+	useJSONStructTags = jsonTags
+	if config.BSONOptions != nil {
+		opts := *config.BSONOptions
+		bsonOptions = &opts
+	}
+	namingStrategy = naming
+	conditionMode = config.ConditionMode
+	updateMode = config.UpdateMode
+	unsetNullOptionals = config.UnsetNullOptionals
+	mongoMetrics = monitors.metrics
+	mongoTracer = monitors.tracer
+	mongoTopologyNotifier = monitors.topology
+	if monitors.rotator != nil {
+		monitors.rotator.start(*credential)
+		mongoCredentials = monitors.rotator
+	}
+	return mongoClient, nil
+}
+
+// clientState 单个客户端及其专属的连接池、拓扑与进行中操作统计
+type clientState struct {
+	client   *mongo.Client
+	pool     *poolTracker
+	topology *topologyTracker
+	inflight *inflightTracker
+	// 需要预热的连接数 未开启预热时为 0
+	warmUp int
+}
+
+// clientMonitors 在凭据轮换出的新客户端间共享的监控组件
+type clientMonitors struct {
+	metrics  *metricsRegistry
+	tracer   trace.Tracer
+	rotator  *credentialRotator
+	topology *topologyNotifier
+}
+
+// dial 构建客户端选项并连接 返回客户端状态与默认数据库
+func dial(config *MongoConfig, monitors *clientMonitors) (*clientState, string, error) {
+	clientOptions, err := config.clientOptions()
+	if err != nil {
+		return nil, "", err
+	}
+	database, err := config.resolveDatabase()
+	if err != nil {
+		return nil, "", err
 	}
 	if database == "" {
-		return nil, ErrMongoDatabaseRequired
+		return nil, "", ErrMongoDatabaseRequired
+	}
+	state, err := config.connect(clientOptions, monitors)
+	if err != nil {
+		return nil, "", err
+	}
+	return state, database, nil
+}
+
+// connect 挂载监控器并连接客户端 每个客户端拥有独立的连接池与拓扑统计
+func (c *MongoConfig) connect(clientOptions *options.ClientOptions, monitors *clientMonitors) (*clientState, error) {
+	state := &clientState{
+		pool:     newPoolTracker(),
+		topology: newTopologyTracker(monitors.topology),
+		inflight: newInflightTracker(),
+	}
+	metrics, tracer := monitors.metrics, monitors.tracer
+	var commandMonitors []*event.CommandMonitor
+	poolMonitors := []*event.PoolMonitor{state.pool.monitor(), c.PoolHooks.monitor(), monitors.rotator.monitor()}
+	serverMonitors := []*event.ServerMonitor{state.topology.monitor()}
+	if c.EnableLogger {
+		commandMonitors = append(commandMonitors, &event.CommandMonitor{
+			Started: func(ctx context.Context, evt *event.CommandStartedEvent) {
+				logger.Logrus().WithField("database", evt.DatabaseName).Traceln(evt.CommandName)
+			},
+		})
+	}
+	if metrics != nil {
+		commandMonitors = append(commandMonitors, metrics.commandMonitor())
+		poolMonitors = append(poolMonitors, metrics.poolMonitor())
+	}
+	if tracer != nil {
+		commandMonitors = append(commandMonitors, tracingCommandMonitor(tracer))
+	}
+	if monitor := combineCommandMonitors(commandMonitors...); monitor != nil {
+		clientOptions.SetMonitor(monitor)
+	}
+	if monitor := combinePoolMonitors(poolMonitors...); monitor != nil {
+		clientOptions.SetPoolMonitor(monitor)
+	}
+	if monitor := combineServerMonitors(serverMonitors...); monitor != nil {
+		clientOptions.SetServerMonitor(monitor)
 	}
 	client, err := mongo.Connect(clientOptions)
 	if err != nil {
-		return nil, err
+		return nil, c.redactError(err)
 	}
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-	defer cancel()
-	if err = client.Ping(ctx, readpref.Primary()); err != nil {
-		_ = client.Disconnect(context.Background())
-		return nil, err
+	state.client = client
+	if c.WarmUpPool && clientOptions.MinPoolSize != nil {
+		state.warmUp = int(*clientOptions.MinPoolSize)
 	}
-	mongoClient = client
-	defaultDatabase = database
-	return mongoClient, nil
+	return state, nil
 }
 
+// publish 将客户端设置为当前使用的客户端 调用方需持有 mongoLock 写锁
+func publish(state *clientState) {
+	mongoClient = state.client
+	mongoPool = state.pool
+	mongoTopology = state.topology
+	mongoInflight = state.inflight
+	state.topology.activate()
+}
+
+// StopTimeoutError 停止超时错误 Running 为超时时仍在进行的 Mapper 操作数量
+type StopTimeoutError struct {
+	Running int64
+}
+
+func (e *StopTimeoutError) Error() string {
+	return fmt.Sprintf("%s: %d operations still running", ErrMongoStopTimeout.Error(), e.Running)
+}
+
+func (e *StopTimeoutError) Is(target error) bool {
+	return target == ErrMongoStopTimeout
+}
+
+// Stop 分阶段停止: 拒绝新的 Mapper 操作 在 maxWaitTime 内等待进行中的操作结束 最后断开连接
+// 等待超时返回 *StopTimeoutError 此时客户端保持连接并继续拒绝新操作 可再次调用 Stop
 func (m *MongoStarter) Stop(maxWaitTime time.Duration) (gracefully, stopped bool, err error) {
+	ctx, cancel := context.WithTimeout(context.Background(), maxWaitTime)
+	defer cancel()
+
 	mongoLock.Lock()
-	defer mongoLock.Unlock()
 	if mongoClient == nil {
+		mongoLock.Unlock()
 		return false, true, ErrMongoStarterNotStarted
 	}
-	ctx, cancel := context.WithTimeout(context.Background(), maxWaitTime)
-	defer cancel()
+	mongoStopping = true
+	if startupCancel != nil {
+		startupCancel()
+		startupCancel = nil
+	}
+	client, inflight, rotator := mongoClient, mongoInflight, mongoCredentials
+	mongoCredentials = nil
+	mongoLock.Unlock()
+
+	if rotator != nil {
+		rotator.stop(ctx)
+	}
+	if running := inflight.wait(ctx); running > 0 {
+		return false, false, &StopTimeoutError{Running: running}
+	}
+
+	mongoLock.Lock()
+	defer mongoLock.Unlock()
+	if mongoClient != client {
+		return false, true, ErrMongoStarterNotStarted
+	}
+	// 主动断开引起的拓扑变化不再回调
+	mongoTopologyNotifier.close()
+	mongoTopologyNotifier = nil
 	if err = mongoClient.Disconnect(ctx); err != nil {
 		if errors.Is(err, context.DeadlineExceeded) || errors.Is(ctx.Err(), context.DeadlineExceeded) {
-			return false, false, ErrMongoStopTimeout
+			return false, false, &StopTimeoutError{Running: inflight.running()}
 		}
 		return false, false, err
 	}
 	mongoClient = nil
+	mongoStopping = false
 	defaultDatabase = ""
+	useJSONStructTags = false
+	bsonOptions = nil
+	namingStrategy = nil
+	conditionMode = ConditionStruct
+	updateMode = UpdateStruct
+	unsetNullOptionals = false
+	pageCountCache.clear()
+	mongoMetrics = nil
+	mongoTracer = nil
+	mongoPool = nil
+	mongoTopology = nil
+	mongoInflight = nil
 	return true, true, nil
 }
 
@@ -171,20 +427,19 @@ func RawDatabase(database ...string) *mongo.Database {
 	if mongoClient == nil {
 		return nil
 	}
-	var db string
-	if len(database) > 0 {
+	db := defaultDatabase
+	if len(database) > 0 && database[0] != "" {
 		db = database[0]
-	} else {
-		db = defaultDatabase
 	}
 	return mongoClient.Database(db)
 }
 
 // RawCollection 获取原始的 mongo.Collection 原生能力
+// collection 按命名策略转换
 func RawCollection(collection string, database ...string) *mongo.Collection {
 	db := RawDatabase(database...)
 	if db == nil {
 		return nil
 	}
-	return db.Collection(collection)
+	return db.Collection(ApplyNaming(collection))
 }
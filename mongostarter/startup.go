@@ -0,0 +1,143 @@
+package mongostarter
+
+import (
+	"context"
+	"fmt"
+	"math/rand/v2"
+	"time"
+
+	"github.com/acexy/golang-toolkit/logger"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/readpref"
+)
+
+// 降级启动时后台重连任务的取消函数
+var startupCancel context.CancelFunc
+
+// StartupConfig 启动时连通性检查配置
+type StartupConfig struct {
+	// 单次 Ping 超时时间 默认 5s
+	PingTimeout time.Duration
+	// Ping 使用的读偏好 默认 readpref.Primary()
+	PingReadPref *readpref.ReadPref
+	// 最大尝试次数 默认 1 即不重试
+	MaxAttempts int
+	// 首次重试前的等待时间 之后每次翻倍 默认 500ms
+	InitialBackoff time.Duration
+	// 重试等待时间上限 默认 10s
+	MaxBackoff time.Duration
+	// 等待时间的随机抖动比例 取值 0~1 nil 或超出范围时为 0.2 设置为 0 时不抖动
+	Jitter *float64
+	// 降级启动 Start 不等待 Ping 成功直接返回 由后台持续重试直到连通或停止
+	Degraded bool
+}
+
+func (c StartupConfig) withDefaults() StartupConfig {
+	if c.PingTimeout <= 0 {
+		c.PingTimeout = 5 * time.Second
+	}
+	if c.PingReadPref == nil {
+		c.PingReadPref = readpref.Primary()
+	}
+	if c.MaxAttempts <= 0 {
+		c.MaxAttempts = 1
+	}
+	if c.InitialBackoff <= 0 {
+		c.InitialBackoff = 500 * time.Millisecond
+	}
+	if c.MaxBackoff <= 0 {
+		c.MaxBackoff = 10 * time.Second
+	}
+	if c.Jitter == nil || *c.Jitter < 0 || *c.Jitter > 1 {
+		jitter := 0.2
+		c.Jitter = &jitter
+	}
+	return c
+}
+
+// backoff 计算第 attempt 次失败后的等待时间 指数增长并叠加随机抖动
+func (c StartupConfig) backoff(attempt int) time.Duration {
+	wait := c.InitialBackoff
+	for i := 1; i < attempt && wait < c.MaxBackoff; i++ {
+		wait *= 2
+	}
+	wait = min(wait, c.MaxBackoff)
+	delta := float64(wait) * *c.Jitter * (rand.Float64()*2 - 1)
+	return wait + time.Duration(delta)
+}
+
+func (c StartupConfig) ping(ctx context.Context, client *mongo.Client) error {
+	ctx, cancel := context.WithTimeout(ctx, c.PingTimeout)
+	defer cancel()
+	return client.Ping(ctx, c.PingReadPref)
+}
+
+// pingWithRetry 按配置的次数与退避策略 Ping 直到成功 maxAttempts 小于等于 0 表示不限次数
+// 重试日志中的错误按 config 脱敏
+func (c StartupConfig) pingWithRetry(ctx context.Context, client *mongo.Client, maxAttempts int, config *MongoConfig) error {
+	var err error
+	for attempt := 1; maxAttempts <= 0 || attempt <= maxAttempts; attempt++ {
+		if err = c.ping(ctx, client); err == nil {
+			return nil
+		}
+		if maxAttempts > 0 && attempt == maxAttempts {
+			break
+		}
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		wait := c.backoff(attempt)
+		logger.Logrus().WithError(config.redactError(err)).Warnf("mongo ping attempt %d failed, retrying in %s", attempt, wait)
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(wait):
+		}
+	}
+	return err
+}
+
+// warmUp 等待驱动为选中的服务端建立 size 个连接 最长等待 PingTimeout
+func (c StartupConfig) warmUp(ctx context.Context, client *mongo.Client, pool *poolTracker, size int) error {
+	if size <= 0 {
+		return nil
+	}
+	// Ping 确保连接池已就绪 驱动随后在后台补足 minPoolSize 个连接
+	if err := c.ping(ctx, client); err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(ctx, c.PingTimeout)
+	defer cancel()
+	ticker := time.NewTicker(50 * time.Millisecond)
+	defer ticker.Stop()
+	for {
+		for _, stats := range pool.snapshot() {
+			if stats.Open >= int64(size) {
+				return nil
+			}
+		}
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("%w: %d connections not established", ErrPoolWarmUpTimeout, size)
+		case <-ticker.C:
+		}
+	}
+}
+
+// connectInBackground 降级启动时在后台持续 Ping 直到连通或被取消 连通后执行 ready
+func (c StartupConfig) connectInBackground(client *mongo.Client, pool *poolTracker, warmUp int, ready func(ctx context.Context) error, config *MongoConfig) context.CancelFunc {
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		if err := c.pingWithRetry(ctx, client, 0, config); err != nil {
+			return
+		}
+		logger.Logrus().Infoln("mongo connectivity established in background")
+		if err := c.warmUp(ctx, client, pool, warmUp); err != nil {
+			logger.Logrus().WithError(config.redactError(err)).Warnln("mongo connection pool warm-up failed")
+		}
+		if err := ready(ctx); err != nil {
+			logger.Logrus().WithError(config.redactError(err)).Warnln("mongo startup tasks failed after connectivity was established")
+		}
+	}()
+	return cancel
+}
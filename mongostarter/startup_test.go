@@ -0,0 +1,102 @@
+package mongostarter
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestStartupConfigWithDefaults(t *testing.T) {
+	jitter := func(value float64) *float64 { return &value }
+	tests := []struct {
+		name     string
+		jitter   *float64
+		expected float64
+	}{
+		{name: "nil", jitter: nil, expected: 0.2},
+		{name: "zero", jitter: jitter(0), expected: 0},
+		{name: "in range", jitter: jitter(0.5), expected: 0.5},
+		{name: "one", jitter: jitter(1), expected: 1},
+		{name: "negative", jitter: jitter(-0.1), expected: 0.2},
+		{name: "above one", jitter: jitter(1.5), expected: 0.2},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			config := StartupConfig{Jitter: test.jitter}.withDefaults()
+			if *config.Jitter != test.expected {
+				t.Fatalf("expected jitter %v, got %v", test.expected, *config.Jitter)
+			}
+		})
+	}
+
+	config := StartupConfig{}.withDefaults()
+	if config.PingTimeout != 5*time.Second || config.MaxAttempts != 1 || config.InitialBackoff != 500*time.Millisecond ||
+		config.MaxBackoff != 10*time.Second || config.PingReadPref == nil {
+		t.Fatalf("unexpected defaults: %+v", config)
+	}
+}
+
+func TestStartupConfigBackoff(t *testing.T) {
+	noJitter := 0.0
+	config := StartupConfig{InitialBackoff: 100 * time.Millisecond, MaxBackoff: time.Second, Jitter: &noJitter}.withDefaults()
+	expected := []time.Duration{100 * time.Millisecond, 200 * time.Millisecond, 400 * time.Millisecond, 800 * time.Millisecond, time.Second, time.Second}
+	for i, wait := range expected {
+		if got := config.backoff(i + 1); got != wait {
+			t.Fatalf("attempt %d: expected %s, got %s", i+1, wait, got)
+		}
+	}
+
+	jitter := 0.5
+	config.Jitter = &jitter
+	for range 200 {
+		for attempt, wait := range expected {
+			got := config.backoff(attempt + 1)
+			if got < wait/2 || got > wait+wait/2 {
+				t.Fatalf("attempt %d: %s is outside the jitter bounds of %s", attempt+1, got, wait)
+			}
+		}
+	}
+}
+
+func TestStartDegraded(t *testing.T) {
+	unreachable := MongoConfig{
+		Database:               "startup_test",
+		Hosts:                  []string{"127.0.0.1:1"},
+		ServerSelectionTimeout: 50 * time.Millisecond,
+		Startup: StartupConfig{
+			PingTimeout:    50 * time.Millisecond,
+			MaxAttempts:    2,
+			InitialBackoff: 10 * time.Millisecond,
+			MaxBackoff:     20 * time.Millisecond,
+		},
+	}
+	starter := &MongoStarter{Config: unreachable}
+	started := time.Now()
+	if _, err := starter.Start(); err == nil || errors.Is(err, ErrInvalidMongoConfig) {
+		t.Fatalf("expected a blocking start against an unreachable host to fail its ping, got %v", err)
+	}
+	if elapsed := time.Since(started); elapsed < 10*time.Millisecond {
+		t.Fatalf("expected the failed ping to be retried after a backoff, took %s", elapsed)
+	}
+	if RawMongoClient() != nil {
+		t.Fatal("expected a failed start to leave no client")
+	}
+
+	degraded := unreachable
+	degraded.Startup.Degraded = true
+	starter = &MongoStarter{Config: degraded}
+	started = time.Now()
+	client, err := starter.Start()
+	if err != nil || client == nil {
+		t.Fatalf("expected degraded start to succeed, got %v", err)
+	}
+	if elapsed := time.Since(started); elapsed > time.Second {
+		t.Fatalf("expected degraded start to return without waiting for the ping, took %s", elapsed)
+	}
+	if _, err = (&MongoStarter{Config: degraded}).Start(); !errors.Is(err, ErrMongoStarterAlreadyStarted) {
+		t.Fatalf("expected ErrMongoStarterAlreadyStarted, got %v", err)
+	}
+	if _, stopped, err := starter.Stop(time.Second); err != nil || !stopped {
+		t.Fatalf("expected degraded starter to stop, got %v %v", stopped, err)
+	}
+}
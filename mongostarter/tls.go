@@ -0,0 +1,244 @@
+package mongostarter
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"net"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/acexy/golang-toolkit/logger"
+)
+
+// TLSConfig TLS 连接配置
+// 文件方式提供的证书会按 ReloadInterval 检查修改时间 变更后在下一次握手时自动重新加载
+type TLSConfig struct {
+	// CA 证书文件路径
+	CAFile string
+	// CA 证书 PEM 内容 与 CAFile 同时设置时合并使用
+	CAPEM []byte
+	// 客户端证书文件路径 需要与 KeyFile 同时设置
+	CertFile string
+	// 客户端私钥文件路径
+	KeyFile string
+	// 客户端证书 PEM 内容 需要与 KeyPEM 同时设置
+	CertPEM []byte
+	// 客户端私钥 PEM 内容
+	KeyPEM []byte
+	// 覆盖用于 SNI 与证书校验的服务端名称
+	ServerName string
+	// 最低 TLS 版本 默认 tls.VersionTLS12
+	MinVersion uint16
+	// 跳过服务端证书校验 仅用于本地开发
+	InsecureSkipVerify bool
+	// 证书文件变更检查间隔 默认 1 分钟
+	ReloadInterval time.Duration
+}
+
+// validate 校验 TLS 配置并加载证书 成功时返回用于建立连接的 reloader
+func (c *TLSConfig) validate(result *ConfigError) *tlsReloader {
+	invalid := len(result.Fields)
+	if c.CertFile != "" && len(c.CertPEM) > 0 {
+		result.add("TLS.CertFile", errors.New("CertFile and CertPEM are mutually exclusive"))
+	}
+	if (c.CertFile == "") != (c.KeyFile == "") {
+		result.add("TLS.KeyFile", errors.New("CertFile and KeyFile must be set together"))
+	}
+	if (len(c.CertPEM) == 0) != (len(c.KeyPEM) == 0) {
+		result.add("TLS.KeyPEM", errors.New("CertPEM and KeyPEM must be set together"))
+	}
+	switch c.MinVersion {
+	case 0, tls.VersionTLS10, tls.VersionTLS11, tls.VersionTLS12, tls.VersionTLS13:
+	default:
+		result.add("TLS.MinVersion", errors.New("unsupported TLS version"))
+	}
+	if c.ReloadInterval < 0 {
+		result.add("TLS.ReloadInterval", errors.New("must not be negative"))
+	}
+	if len(result.Fields) > invalid {
+		return nil
+	}
+	reloader, err := newTLSReloader(c)
+	if err != nil {
+		result.Fields = append(result.Fields, err)
+		return nil
+	}
+	return reloader
+}
+
+type tlsReloader struct {
+	config *TLSConfig
+
+	mu          sync.RWMutex
+	checkedAt   time.Time
+	modTimes    map[string]time.Time
+	roots       *x509.CertPool
+	certificate *tls.Certificate
+}
+
+func newTLSReloader(config *TLSConfig) (*tlsReloader, *FieldError) {
+	r := &tlsReloader{config: config}
+	if err := r.load(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// load 读取全部证书材料 成功后整体替换当前使用的证书
+func (r *tlsReloader) load() *FieldError {
+	c := r.config
+	modTimes := make(map[string]time.Time)
+	var roots *x509.CertPool
+	if c.CAFile != "" || len(c.CAPEM) > 0 {
+		roots = x509.NewCertPool()
+		if c.CAFile != "" {
+			data, err := readWithModTime(c.CAFile, modTimes)
+			if err != nil {
+				return &FieldError{Field: "TLS.CAFile", Err: err}
+			}
+			if !roots.AppendCertsFromPEM(data) {
+				return &FieldError{Field: "TLS.CAFile", Err: errors.New("no valid certificate found")}
+			}
+		}
+		if len(c.CAPEM) > 0 && !roots.AppendCertsFromPEM(c.CAPEM) {
+			return &FieldError{Field: "TLS.CAPEM", Err: errors.New("no valid certificate found")}
+		}
+	}
+	var certificate *tls.Certificate
+	if c.CertFile != "" {
+		certPEM, err := readWithModTime(c.CertFile, modTimes)
+		if err != nil {
+			return &FieldError{Field: "TLS.CertFile", Err: err}
+		}
+		keyPEM, err := readWithModTime(c.KeyFile, modTimes)
+		if err != nil {
+			return &FieldError{Field: "TLS.KeyFile", Err: err}
+		}
+		pair, err := tls.X509KeyPair(certPEM, keyPEM)
+		if err != nil {
+			return &FieldError{Field: "TLS.CertFile", Err: err}
+		}
+		certificate = &pair
+	} else if len(c.CertPEM) > 0 {
+		pair, err := tls.X509KeyPair(c.CertPEM, c.KeyPEM)
+		if err != nil {
+			return &FieldError{Field: "TLS.CertPEM", Err: err}
+		}
+		certificate = &pair
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.roots = roots
+	r.certificate = certificate
+	r.modTimes = modTimes
+	r.checkedAt = time.Now()
+	return nil
+}
+
+func readWithModTime(path string, modTimes map[string]time.Time) ([]byte, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	modTimes[path] = info.ModTime()
+	return os.ReadFile(path)
+}
+
+// refresh 到达检查间隔后比较文件修改时间 有变化时重新加载 加载失败继续使用旧证书
+func (r *tlsReloader) refresh() {
+	interval := r.config.ReloadInterval
+	if interval <= 0 {
+		interval = time.Minute
+	}
+	r.mu.Lock()
+	if len(r.modTimes) == 0 || time.Since(r.checkedAt) < interval {
+		r.mu.Unlock()
+		return
+	}
+	r.checkedAt = time.Now()
+	changed := false
+	for path, modTime := range r.modTimes {
+		if info, err := os.Stat(path); err == nil && !info.ModTime().Equal(modTime) {
+			changed = true
+			break
+		}
+	}
+	r.mu.Unlock()
+	if !changed {
+		return
+	}
+	if err := r.load(); err != nil {
+		logger.Logrus().WithError(err).Warnln("mongo TLS certificate reload failed, keep using the previous certificate")
+		return
+	}
+	logger.Logrus().Infoln("mongo TLS certificate reloaded")
+}
+
+// hostConfig 为目标主机构建 tls.Config 使用当前证书 由标准库校验证书链以及主机名或 IP
+// ServerName 为空时使用拨号的主机 因此每条连接都按实际主机校验
+func (r *tlsReloader) hostConfig(host string) *tls.Config {
+	r.refresh()
+	r.mu.RLock()
+	roots, certificate := r.roots, r.certificate
+	r.mu.RUnlock()
+	c := r.config
+	config := &tls.Config{
+		ServerName:         c.ServerName,
+		MinVersion:         c.MinVersion,
+		RootCAs:            roots,
+		InsecureSkipVerify: c.InsecureSkipVerify,
+	}
+	if config.ServerName == "" {
+		config.ServerName = host
+	}
+	if config.MinVersion == 0 {
+		config.MinVersion = tls.VersionTLS12
+	}
+	if certificate != nil {
+		config.Certificates = []tls.Certificate{*certificate}
+	}
+	return config
+}
+
+// tlsDialer 建立 TCP 连接后按目标主机完成 TLS 握手 替代驱动内置的 TLS 以便每次握手使用热更新的证书
+type tlsDialer struct {
+	reloader *tlsReloader
+	dialer   net.Dialer
+	ocsp     *ocspVerifier
+}
+
+func (r *tlsReloader) dialer() *tlsDialer {
+	return &tlsDialer{reloader: r, dialer: net.Dialer{KeepAlive: 2 * time.Minute}, ocsp: newOCSPVerifier()}
+}
+
+// DialContext 无法确定服务端名称时拒绝连接
+func (d *tlsDialer) DialContext(ctx context.Context, network, address string) (net.Conn, error) {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		host = address
+	}
+	config := d.reloader.hostConfig(host)
+	if config.ServerName == "" && !config.InsecureSkipVerify {
+		return nil, errors.New("mongo TLS has no server name to verify for " + address)
+	}
+	conn, err := d.dialer.DialContext(ctx, network, address)
+	if err != nil {
+		return nil, err
+	}
+	client := tls.Client(conn, config)
+	if err = client.HandshakeContext(ctx); err != nil {
+		_ = conn.Close()
+		return nil, err
+	}
+	// 与驱动内置 TLS 一致 校验证书时同时检查 OCSP 吊销状态
+	if !config.InsecureSkipVerify {
+		if err = d.ocsp.verify(ctx, client.ConnectionState()); err != nil {
+			_ = client.Close()
+			return nil, err
+		}
+	}
+	return client, nil
+}
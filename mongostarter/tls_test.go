@@ -0,0 +1,168 @@
+package mongostarter
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+)
+
+// testPEM 生成自签名证书与私钥的 PEM 内容
+func testPEM(t *testing.T, commonName string) (certPEM, keyPEM []byte) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	serial, err := rand.Int(rand.Reader, big.NewInt(1<<62))
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber:          serial,
+		Subject:               pkix.Name{CommonName: commonName},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth, x509.ExtKeyUsageServerAuth},
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+}
+
+func writeFile(t *testing.T, path string, data []byte, modTime time.Time) {
+	t.Helper()
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chtimes(path, modTime, modTime); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func fieldNames(result *ConfigError) []string {
+	names := make([]string, 0, len(result.Fields))
+	for _, field := range result.Fields {
+		names = append(names, field.Field)
+	}
+	return names
+}
+
+func TestTLSConfigValidate(t *testing.T) {
+	dir := t.TempDir()
+	certPEM, keyPEM := testPEM(t, "client")
+	certFile, keyFile, caFile := filepath.Join(dir, "client.crt"), filepath.Join(dir, "client.key"), filepath.Join(dir, "ca.crt")
+	writeFile(t, certFile, certPEM, time.Now())
+	writeFile(t, keyFile, keyPEM, time.Now())
+	writeFile(t, caFile, certPEM, time.Now())
+
+	tests := []struct {
+		name   string
+		config TLSConfig
+		fields []string
+	}{
+		{name: "files", config: TLSConfig{CAFile: caFile, CertFile: certFile, KeyFile: keyFile}},
+		{name: "pem", config: TLSConfig{CAPEM: certPEM, CertPEM: certPEM, KeyPEM: keyPEM}},
+		{name: "combinations", config: TLSConfig{CertFile: certFile, CertPEM: certPEM, MinVersion: 1, ReloadInterval: -1},
+			fields: []string{"TLS.CertFile", "TLS.KeyFile", "TLS.KeyPEM", "TLS.MinVersion", "TLS.ReloadInterval"}},
+		{name: "missing CA file", config: TLSConfig{CAFile: filepath.Join(dir, "missing.crt")}, fields: []string{"TLS.CAFile"}},
+		{name: "invalid CA PEM", config: TLSConfig{CAPEM: []byte("not a certificate")}, fields: []string{"TLS.CAPEM"}},
+		{name: "mismatched key", config: TLSConfig{CertPEM: certPEM, KeyPEM: certPEM}, fields: []string{"TLS.CertPEM"}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			result := &ConfigError{}
+			reloader := test.config.validate(result)
+			if names := fieldNames(result); !slices.Equal(names, test.fields) {
+				t.Fatalf("expected invalid fields %v, got %v", test.fields, names)
+			}
+			if (reloader != nil) != (len(test.fields) == 0) {
+				t.Fatalf("unexpected reloader %v for fields %v", reloader, test.fields)
+			}
+			if reloader != nil && reloader.certificate == nil {
+				t.Fatal("expected the client certificate to be loaded")
+			}
+		})
+	}
+}
+
+func TestTLSReloaderRefresh(t *testing.T) {
+	dir := t.TempDir()
+	certFile, keyFile := filepath.Join(dir, "client.crt"), filepath.Join(dir, "client.key")
+	firstCert, firstKey := testPEM(t, "first")
+	loadedAt := time.Now().Add(-time.Minute)
+	writeFile(t, certFile, firstCert, loadedAt)
+	writeFile(t, keyFile, firstKey, loadedAt)
+
+	result := &ConfigError{}
+	reloader := (&TLSConfig{CertFile: certFile, KeyFile: keyFile, ReloadInterval: time.Nanosecond}).validate(result)
+	if reloader == nil {
+		t.Fatalf("unexpected validation error: %v", result)
+	}
+	current := func() []byte {
+		t.Helper()
+		certificates := reloader.hostConfig("db.example.com").Certificates
+		if len(certificates) != 1 {
+			t.Fatalf("expected one client certificate, got %d", len(certificates))
+		}
+		return certificates[0].Certificate[0]
+	}
+	first := current()
+
+	reloader.refresh()
+	if !bytes.Equal(current(), first) {
+		t.Fatal("expected unchanged files to keep the certificate")
+	}
+
+	secondCert, secondKey := testPEM(t, "second")
+	writeFile(t, certFile, secondCert, loadedAt.Add(time.Second))
+	writeFile(t, keyFile, secondKey, loadedAt.Add(time.Second))
+	second := current()
+	if bytes.Equal(second, first) {
+		t.Fatal("expected modified files to be reloaded")
+	}
+
+	writeFile(t, certFile, []byte("broken"), loadedAt.Add(2*time.Second))
+	if !bytes.Equal(current(), second) {
+		t.Fatal("expected a failed reload to keep the previous certificate")
+	}
+}
+
+func TestTLSHostConfig(t *testing.T) {
+	result := &ConfigError{}
+	reloader := (&TLSConfig{}).validate(result)
+	if reloader == nil {
+		t.Fatalf("unexpected validation error: %v", result)
+	}
+	for _, host := range []string{"db.example.com", "10.0.0.5"} {
+		config := reloader.hostConfig(host)
+		if config.ServerName != host || config.MinVersion != tls.VersionTLS12 || config.InsecureSkipVerify {
+			t.Fatalf("unexpected config for %s: %+v", host, config)
+		}
+	}
+
+	reloader = (&TLSConfig{ServerName: "mongo.internal", MinVersion: tls.VersionTLS13}).validate(result)
+	if config := reloader.hostConfig("10.0.0.5"); config.ServerName != "mongo.internal" || config.MinVersion != tls.VersionTLS13 {
+		t.Fatalf("expected ServerName and MinVersion to override the defaults: %+v", config)
+	}
+}
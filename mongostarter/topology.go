@@ -0,0 +1,298 @@
+package mongostarter
+
+import (
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/acexy/golang-toolkit/logger"
+	"go.mongodb.org/mongo-driver/v2/event"
+)
+
+var (
+	mongoTopology         *topologyTracker
+	mongoTopologyNotifier *topologyNotifier
+)
+
+// TopologyMember 拓扑中单个节点的状态
+type TopologyMember struct {
+	// 节点地址
+	Address string `json:"address"`
+	// 节点状态 如 RSPrimary、RSSecondary、RSArbiter、Standalone、Mongos、Unknown
+	State string `json:"state"`
+	// 心跳往返时延的指数加权平均值 尚未测得时为 0
+	RoundTripTime time.Duration `json:"roundTripTime"`
+	// 最近一次心跳失败原因 心跳恢复后清空
+	Error string `json:"error,omitempty"`
+}
+
+// TopologyStatus 当前拓扑状态
+type TopologyStatus struct {
+	// 拓扑类型 如 Single、ReplicaSetWithPrimary、ReplicaSetNoPrimary、Sharded
+	Kind string `json:"kind"`
+	// 副本集名称
+	SetName string `json:"setName,omitempty"`
+	// 主节点地址 无主节点时为空
+	Primary string `json:"primary,omitempty"`
+	// 按地址排序的节点列表
+	Members []TopologyMember `json:"members"`
+}
+
+// Available 是否存在可读写的数据节点
+func (s TopologyStatus) Available() bool {
+	for _, member := range s.Members {
+		switch member.State {
+		case "RSPrimary", "RSSecondary", "Standalone", "Mongos", "LoadBalancer":
+			return true
+		}
+	}
+	return false
+}
+
+// TopologyHooks 连接生命周期与拓扑变化回调
+// 回调在独立的协程中按事件顺序执行 Stop 开始断开连接后不再回调
+type TopologyHooks struct {
+	// 首次连通
+	OnConnected func(status TopologyStatus)
+	// 主节点变化 previous 为空表示此前没有主节点 status.Primary 为空表示主节点丢失
+	OnPrimaryChanged func(previous string, status TopologyStatus)
+	// 失去全部可用节点
+	OnDisconnected func(status TopologyStatus)
+	// 断开后恢复连通
+	OnReconnected func(status TopologyStatus)
+}
+
+// Topology 获取当前拓扑状态 包括各节点的状态与往返时延
+func Topology() (*TopologyStatus, error) {
+	mongoLock.RLock()
+	topology := mongoTopology
+	mongoLock.RUnlock()
+	if topology == nil {
+		return nil, ErrMongoStarterNotStarted
+	}
+	status := topology.status()
+	return &status, nil
+}
+
+// topologyTracker 记录驱动上报的最新拓扑描述与各节点心跳时延
+type topologyTracker struct {
+	notifier *topologyNotifier
+
+	mu          sync.RWMutex
+	description event.TopologyDescription
+	rtt         map[string]time.Duration
+	failures    map[string]string
+}
+
+func newTopologyTracker(notifier *topologyNotifier) *topologyTracker {
+	return &topologyTracker{
+		notifier:    notifier,
+		description: event.TopologyDescription{Kind: "Unknown"},
+		rtt:         make(map[string]time.Duration),
+		failures:    make(map[string]string),
+	}
+}
+
+func (t *topologyTracker) monitor() *event.ServerMonitor {
+	return &event.ServerMonitor{
+		TopologyDescriptionChanged: func(evt *event.TopologyDescriptionChangedEvent) {
+			t.mu.Lock()
+			t.description = evt.NewDescription
+			t.mu.Unlock()
+			t.notifier.update(t)
+		},
+		ServerHeartbeatSucceeded: func(evt *event.ServerHeartbeatSucceededEvent) {
+			address := heartbeatAddress(evt.ConnectionID)
+			t.mu.Lock()
+			defer t.mu.Unlock()
+			delete(t.failures, address)
+			// 流式心跳的耗时包含服务端的等待时间 不能作为往返时延
+			if evt.Awaited {
+				return
+			}
+			if previous, ok := t.rtt[address]; ok {
+				t.rtt[address] = time.Duration(0.2*float64(evt.Duration) + 0.8*float64(previous))
+			} else {
+				t.rtt[address] = evt.Duration
+			}
+		},
+		ServerHeartbeatFailed: func(evt *event.ServerHeartbeatFailedEvent) {
+			address := heartbeatAddress(evt.ConnectionID)
+			t.mu.Lock()
+			defer t.mu.Unlock()
+			if evt.Failure != nil {
+				t.failures[address] = evt.Failure.Error()
+			}
+		},
+	}
+}
+
+// heartbeatAddress 从形如 host:port[-1] 的连接标识中提取地址
+func heartbeatAddress(connectionID string) string {
+	if index := strings.LastIndex(connectionID, "[-"); index > 0 {
+		return connectionID[:index]
+	}
+	return connectionID
+}
+
+func (t *topologyTracker) current() event.TopologyDescription {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	return t.description
+}
+
+func (t *topologyTracker) status() TopologyStatus {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	status := TopologyStatus{
+		Kind:    t.description.Kind,
+		SetName: t.description.SetName,
+		Members: make([]TopologyMember, 0, len(t.description.Servers)),
+	}
+	for _, server := range t.description.Servers {
+		address := server.Addr.String()
+		if server.Kind == "RSPrimary" {
+			status.Primary = address
+		}
+		status.Members = append(status.Members, TopologyMember{
+			Address:       address,
+			State:         server.Kind,
+			RoundTripTime: t.rtt[address],
+			Error:         t.failures[address],
+		})
+	}
+	sort.Slice(status.Members, func(i, j int) bool {
+		return status.Members[i].Address < status.Members[j].Address
+	})
+	return status
+}
+
+// activate 将当前追踪器设为回调的事件来源
+func (t *topologyTracker) activate() {
+	t.notifier.activate(t)
+}
+
+// topologyNotifier 根据拓扑变化触发 TopologyHooks
+// 凭据轮换替换客户端后只处理当前客户端的事件 回调由独立协程执行 不阻塞驱动与 mongoLock
+type topologyNotifier struct {
+	hooks  *TopologyHooks
+	events chan func()
+	done   chan struct{}
+
+	mu        sync.Mutex
+	active    *topologyTracker
+	closed    bool
+	connected bool
+	everUp    bool
+	primary   string
+}
+
+func newTopologyNotifier(hooks *TopologyHooks) *topologyNotifier {
+	if hooks == nil {
+		return nil
+	}
+	n := &topologyNotifier{
+		hooks:  hooks,
+		events: make(chan func(), 256),
+		done:   make(chan struct{}),
+	}
+	go func() {
+		for {
+			select {
+			case <-n.done:
+				return
+			case fn := <-n.events:
+				fn()
+			}
+		}
+	}()
+	return n
+}
+
+func (n *topologyNotifier) activate(t *topologyTracker) {
+	if n == nil {
+		return
+	}
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.active = t
+	n.evaluate(t.status())
+}
+
+func (n *topologyNotifier) update(t *topologyTracker) {
+	if n == nil {
+		return
+	}
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if n.active != t {
+		return
+	}
+	n.evaluate(t.status())
+}
+
+// close 停止回调 尚未执行的回调被丢弃
+func (n *topologyNotifier) close() {
+	if n == nil {
+		return
+	}
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if n.closed {
+		return
+	}
+	n.closed = true
+	n.active = nil
+	close(n.done)
+}
+
+// evaluate 比较连通状态与主节点 发生变化时排队执行对应回调 调用方需持有 n.mu
+func (n *topologyNotifier) evaluate(status TopologyStatus) {
+	if n.closed {
+		return
+	}
+	available := status.Available()
+	switch {
+	case available && !n.connected && !n.everUp:
+		n.enqueue(n.hooks.OnConnected, status)
+	case available && !n.connected:
+		n.enqueue(n.hooks.OnReconnected, status)
+	case !available && n.connected:
+		n.enqueue(n.hooks.OnDisconnected, status)
+	}
+	n.connected = available
+	n.everUp = n.everUp || available
+	if status.Primary != n.primary {
+		previous := n.primary
+		n.primary = status.Primary
+		if hook := n.hooks.OnPrimaryChanged; hook != nil {
+			n.push(func() { hook(previous, status) })
+		}
+	}
+}
+
+func (n *topologyNotifier) enqueue(hook func(TopologyStatus), status TopologyStatus) {
+	if hook != nil {
+		n.push(func() { hook(status) })
+	}
+}
+
+func (n *topologyNotifier) push(fn func()) {
+	select {
+	case n.events <- fn:
+	default:
+		logger.Logrus().Warnln("mongo topology hook queue is full, event dropped")
+	}
+}
+
+// primaryAvailable 判断当前拓扑是否存在可写节点
+func primaryAvailable(description event.TopologyDescription) bool {
+	for _, server := range description.Servers {
+		switch server.Kind {
+		case "RSPrimary", "Standalone", "Mongos", "LoadBalancer":
+			return true
+		}
+	}
+	return false
+}
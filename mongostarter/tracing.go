@@ -0,0 +1,99 @@
+package mongostarter
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	"go.mongodb.org/mongo-driver/v2/event"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const tracerName = "github.com/golang-acexy/starter-mongo/mongostarter"
+
+var mongoTracer trace.Tracer
+
+// TracingConfig 链路追踪配置
+type TracingConfig struct {
+	// 为空时使用 otel 全局 TracerProvider
+	TracerProvider trace.TracerProvider
+}
+
+func newTracer(config *TracingConfig) trace.Tracer {
+	provider := config.TracerProvider
+	if provider == nil {
+		provider = otel.GetTracerProvider()
+	}
+	return provider.Tracer(tracerName)
+}
+
+// tracingCommandMonitor 为每个驱动命令创建 Client Span 父 Span 来自执行命令时传入的 context
+func tracingCommandMonitor(tracer trace.Tracer) *event.CommandMonitor {
+	var spans sync.Map
+	return &event.CommandMonitor{
+		Started: func(ctx context.Context, evt *event.CommandStartedEvent) {
+			collection := commandCollection(evt)
+			name := evt.CommandName
+			if collection != "" {
+				name += " " + collection
+			}
+			_, span := tracer.Start(ctx, name,
+				trace.WithSpanKind(trace.SpanKindClient),
+				trace.WithAttributes(
+					attribute.String("db.system.name", "mongodb"),
+					attribute.String("db.namespace", evt.DatabaseName),
+					attribute.String("db.collection.name", collection),
+					attribute.String("db.operation.name", evt.CommandName),
+				))
+			spans.Store(evt.RequestID, span)
+		},
+		Succeeded: func(_ context.Context, evt *event.CommandSucceededEvent) {
+			if value, ok := spans.LoadAndDelete(evt.RequestID); ok {
+				value.(trace.Span).End()
+			}
+		},
+		Failed: func(_ context.Context, evt *event.CommandFailedEvent) {
+			if value, ok := spans.LoadAndDelete(evt.RequestID); ok {
+				span := value.(trace.Span)
+				span.RecordError(evt.Failure)
+				span.SetStatus(codes.Error, evt.Failure.Error())
+				span.End()
+			}
+		},
+	}
+}
+
+// startMapperSpan 创建 Mapper 方法级别的 Span 未启用链路追踪时原样返回 context
+func startMapperSpan(ctx context.Context, operation string, coll *mongo.Collection) (context.Context, trace.Span) {
+	mongoLock.RLock()
+	tracer := mongoTracer
+	mongoLock.RUnlock()
+	if tracer == nil {
+		return ctx, nil
+	}
+	return tracer.Start(ctx, operation+" "+coll.Name(),
+		trace.WithSpanKind(trace.SpanKindInternal),
+		trace.WithAttributes(
+			attribute.String("db.system.name", "mongodb"),
+			attribute.String("db.namespace", coll.Database().Name()),
+			attribute.String("db.collection.name", coll.Name()),
+			attribute.String("db.operation.name", operation),
+		))
+}
+
+// endMapperSpan 记录文档数量与错误并结束 Span
+func endMapperSpan(span trace.Span, count int64, err error) {
+	if span == nil {
+		return
+	}
+	span.SetAttributes(attribute.Int64("db.mongodb.document_count", count))
+	if err != nil && !errors.Is(err, mongo.ErrNoDocuments) {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
@@ -0,0 +1,272 @@
+package mongostarter
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+// VersionedModel 模型实现该接口时 SaveChanges 使用乐观锁
+// 更新条件包含快照中的版本号 更新成功后版本号加一 版本号不一致时返回 ErrVersionConflict
+type VersionedModel interface {
+	// VersionField 版本号字段的 bson 名称 嵌套字段使用点号路径
+	VersionField() string
+}
+
+// Tracked 带快照的实体 修改 Entity 后通过 SaveChanges 只写入变更的字段
+type Tracked[T Model] struct {
+	// Entity 当前实体 可直接修改
+	Entity *T
+	// 加载时解析的集合名称 不包含命名策略的前缀与后缀
+	collection string
+	// 数据库中的主键 用于保存时定位文档
+	id any
+	// Entity 在加载或上次保存后的编码结果
+	snapshot bson.Raw
+}
+
+// Changes 获取当前实体相对快照的更新文档 无变更时返回 nil 不包含版本号递增
+func (t *Tracked[T]) Changes() (bson.M, error) {
+	set, unset, err := t.diff()
+	if err != nil {
+		return nil, err
+	}
+	return updateDocument(set, unset), nil
+}
+
+// diff 比较当前实体与快照 嵌套文档展开为点号路径 数组整体替换
+func (t *Tracked[T]) diff() (set, unset bson.D, err error) {
+	if t == nil || t.Entity == nil || t.id == nil {
+		return nil, nil, ErrNotTracked
+	}
+	current, err := marshalDocument(t.Entity)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err = diffSnapshot(t.snapshot, current, "", &set, &unset); err != nil {
+		return nil, nil, err
+	}
+	for _, e := range slices.Concat(set, unset) {
+		if e.Key == "_id" {
+			return nil, nil, fmt.Errorf("%w: _id cannot be changed", ErrNotTracked)
+		}
+	}
+	return set, unset, nil
+}
+
+// Track 为已加载的实体创建快照 集合按实体解析
+// 默认将字符串主键转换为 ObjectID；普通字符串 ID 需要将 notObjectID 设置为 true
+func (b BaseMapper[T]) Track(entity *T, notObjectID ...bool) (*Tracked[T], error) {
+	snapshot, err := marshalDocument(entity)
+	if err != nil {
+		return nil, err
+	}
+	value, err := snapshot.LookupErr("_id")
+	if err != nil {
+		return nil, fmt.Errorf("%w: entity has no _id", ErrNotTracked)
+	}
+	var id any = value
+	if value.Type == bson.TypeString {
+		if id, err = b.convertID(value.StringValue(), notObjectID...); err != nil {
+			return nil, err
+		}
+	}
+	return &Tracked[T]{Entity: entity, collection: b.collectionName(b.operationContext(), entity), id: id, snapshot: snapshot}, nil
+}
+
+// TrackByID 通过主键加载数据并创建快照 默认将字符串 ID 转换为 ObjectID；普通字符串 ID 需要将 notObjectID 设置为 true
+func (b BaseMapper[T]) TrackByID(id any, notObjectID ...bool) (*Tracked[T], error) {
+	queryID, err := b.convertID(id, notObjectID...)
+	if err != nil {
+		return nil, err
+	}
+	return b.track("TrackByID", bson.M{"_id": queryID})
+}
+
+// TrackOneByBSON 通过 BSON 条件加载一条数据并创建快照
+func (b BaseMapper[T]) TrackOneByBSON(condition bson.M) (*Tracked[T], error) {
+	return b.track("TrackOneByBSON", condition)
+}
+
+// track 加载一条数据 主键取自数据库中的原始文档
+func (b BaseMapper[T]) track(operation string, filter any) (*Tracked[T], error) {
+	entity := new(T)
+	var id bson.RawValue
+	err := b.execute(operation, func(ctx context.Context, coll *mongo.Collection) (int64, error) {
+		result := coll.FindOne(ctx, filter)
+		raw, err := result.Raw()
+		if err != nil {
+			return 0, err
+		}
+		id = raw.Lookup("_id")
+		return singleCount(result.Decode(entity))
+	})
+	if err != nil {
+		return nil, err
+	}
+	snapshot, err := marshalDocument(entity)
+	if err != nil {
+		return nil, err
+	}
+	return &Tracked[T]{Entity: entity, collection: b.collectionName(b.operationContext(), nil), id: id, snapshot: snapshot}, nil
+}
+
+// SaveChanges 将实体相对快照的变更以 $set/$unset 写入 无变更时不访问数据库并返回 0
+// 保存成功后 Entity 与快照更新为数据库中的最新文档 文档不存在时返回 mongo.ErrNoDocuments
+func (b BaseMapper[T]) SaveChanges(tracked *Tracked[T]) (modified int64, err error) {
+	set, unset, err := tracked.diff()
+	if err != nil {
+		return 0, err
+	}
+	if len(set) == 0 && len(unset) == 0 {
+		return 0, nil
+	}
+	filter := bson.D{{Key: "_id", Value: tracked.id}}
+	document := updateDocument(set, unset)
+	versionField := ""
+	if versioned, ok := any(b.model).(VersionedModel); ok {
+		versionField = versioned.VersionField()
+		var version any
+		if value, err := tracked.snapshot.LookupErr(strings.Split(versionField, ".")...); err == nil {
+			version = value
+		}
+		for _, e := range slices.Concat(set, unset) {
+			if strings.HasPrefix(versionField, e.Key+".") {
+				return 0, fmt.Errorf("%w: %s contains version field %s", ErrVersionPath, e.Key, versionField)
+			}
+		}
+		filter = append(filter, bson.E{Key: versionField, Value: version})
+		document = updateDocument(withoutPath(set, versionField), withoutPath(unset, versionField))
+		if document == nil {
+			document = bson.M{}
+		}
+		document["$inc"] = bson.D{{Key: versionField, Value: 1}}
+	}
+	entity := new(T)
+	opt := options.FindOneAndUpdate().SetReturnDocument(options.After)
+	err = b.executeIn(tracked.collection, "SaveChanges", func(ctx context.Context, coll *mongo.Collection) (int64, error) {
+		return singleCount(checkSingleResult(coll.FindOneAndUpdate(ctx, filter, document, opt), entity))
+	})
+	if err != nil {
+		if versionField != "" && errors.Is(err, mongo.ErrNoDocuments) {
+			return 0, ErrVersionConflict
+		}
+		return 0, err
+	}
+	snapshot, err := marshalDocument(entity)
+	if err != nil {
+		return 0, err
+	}
+	*tracked.Entity = *entity
+	tracked.snapshot = snapshot
+	return 1, nil
+}
+
+// marshalDocument 按当前客户端的 BSONOptions 编码实体 与驱动写入的文档保持一致
+func marshalDocument(entity any) (bson.Raw, error) {
+	buf := new(bytes.Buffer)
+	encoder := bson.NewEncoder(bson.NewDocumentWriter(buf))
+	if opts := currentBSONOptions(); opts != nil {
+		if opts.ErrorOnInlineDuplicates {
+			encoder.ErrorOnInlineDuplicates()
+		}
+		if opts.IntMinSize {
+			encoder.IntMinSize()
+		}
+		if opts.NilByteSliceAsEmpty {
+			encoder.NilByteSliceAsEmpty()
+		}
+		if opts.NilMapAsEmpty {
+			encoder.NilMapAsEmpty()
+		}
+		if opts.NilSliceAsEmpty {
+			encoder.NilSliceAsEmpty()
+		}
+		if opts.OmitZeroStruct {
+			encoder.OmitZeroStruct()
+		}
+		if opts.OmitEmpty {
+			encoder.OmitEmpty()
+		}
+		if opts.StringifyMapKeysWithFmt {
+			encoder.StringifyMapKeysWithFmt()
+		}
+		if opts.UseJSONStructTags {
+			encoder.UseJSONStructTags()
+		}
+	}
+	if err := encoder.Encode(entity); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// diffSnapshot 比较两个文档 新增或变化的字段放入 set 删除的字段放入 unset
+func diffSnapshot(before, after bson.Raw, prefix string, set, unset *bson.D) error {
+	elements, err := after.Elements()
+	if err != nil {
+		return err
+	}
+	for _, element := range elements {
+		key := element.Key()
+		path := joinPath(prefix, key)
+		value := element.Value()
+		previous, err := before.LookupErr(key)
+		if err != nil {
+			*set = append(*set, bson.E{Key: path, Value: value})
+			continue
+		}
+		if value.Type == bson.TypeEmbeddedDocument && previous.Type == bson.TypeEmbeddedDocument {
+			if err = diffSnapshot(previous.Document(), value.Document(), path, set, unset); err != nil {
+				return err
+			}
+			continue
+		}
+		if value.Type != previous.Type || !bytes.Equal(value.Value, previous.Value) {
+			*set = append(*set, bson.E{Key: path, Value: value})
+		}
+	}
+	previousElements, err := before.Elements()
+	if err != nil {
+		return err
+	}
+	for _, element := range previousElements {
+		if _, err = after.LookupErr(element.Key()); err != nil {
+			*unset = append(*unset, bson.E{Key: joinPath(prefix, element.Key()), Value: ""})
+		}
+	}
+	return nil
+}
+
+// withoutPath 移除指定路径及其子路径
+func withoutPath(fields bson.D, path string) bson.D {
+	result := make(bson.D, 0, len(fields))
+	for _, e := range fields {
+		if e.Key != path && !strings.HasPrefix(e.Key, path+".") {
+			result = append(result, e)
+		}
+	}
+	return result
+}
+
+// updateDocument 由 $set 与 $unset 字段生成更新文档 均为空时返回 nil
+func updateDocument(set, unset bson.D) bson.M {
+	if len(set) == 0 && len(unset) == 0 {
+		return nil
+	}
+	document := bson.M{}
+	if len(set) > 0 {
+		document["$set"] = set
+	}
+	if len(unset) > 0 {
+		document["$unset"] = unset
+	}
+	return document
+}
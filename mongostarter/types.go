@@ -1,6 +1,7 @@
 package mongostarter
 
 import (
+	"context"
 	"time"
 
 	"github.com/acexy/golang-toolkit/util/json"
@@ -16,7 +17,17 @@ type Model interface {
 
 // BaseMapper 接口声明
 type BaseMapper[T Model] struct {
-	model T
+	model      T
+	ctx        context.Context
+	database   string
+	collection string
+	readWrite  *ReadWriteOptions
+	// 实体条件模式 nil 时使用 MongoConfig.ConditionMode
+	conditionMode *ConditionMode
+	matchZero     []string
+	// 实体更新模式 nil 时使用 MongoConfig.UpdateMode
+	updateMode *UpdateMode
+	fieldMask  []string
 }
 
 // OrderBy 排序规则
@@ -33,8 +44,16 @@ type PageQuery struct {
 	PageSize       int
 	OrderBy        []*OrderBy
 	SpecifyColumns []string
+	// 排除的字段 不能与 SpecifyColumns 同时指定 SelectPageWithOptions 不使用
+	ExcludeColumns []string
 	FindOptions    []options.Lister[options.FindOptions]
 	CountOptions   []options.Lister[options.CountOptions]
+	// 总数统计方式 默认精确统计
+	CountStrategy CountStrategy
+	// CountCapped 时的统计上限
+	CountLimit int64
+	// CountCached 时的缓存有效期 默认 1 分钟
+	CountCacheTTL time.Duration
 }
 
 // NewOrderBy 新增排序规则
@@ -223,6 +242,21 @@ type DeleteMapper[T Model] interface {
 	DeleteWithOptions(filter any, opts ...options.Lister[options.DeleteManyOptions]) (int64, error)
 }
 
+// TrackMapper 提供带快照的加载与变更保存能力 不包含在 Mapper 中 BaseMapper 实现该接口。
+type TrackMapper[T Model] interface {
+	// Track 为已加载的实体创建快照
+	Track(entity *T, notObjectID ...bool) (*Tracked[T], error)
+
+	// TrackByID 通过主键加载数据并创建快照
+	TrackByID(id any, notObjectID ...bool) (*Tracked[T], error)
+
+	// TrackOneByBSON 通过 BSON 条件加载一条数据并创建快照
+	TrackOneByBSON(condition bson.M) (*Tracked[T], error)
+
+	// SaveChanges 只写入实体相对快照变更的字段
+	SaveChanges(tracked *Tracked[T]) (int64, error)
+}
+
 // Mapper 聚合原始 Collection、查询、插入、更新和删除能力。
 type Mapper[T Model] interface {
 	RawMapper
@@ -0,0 +1,150 @@
+package mongostarter
+
+import (
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// 全局实体更新模式 受 mongoLock 保护
+var updateMode UpdateMode
+
+// UpdateMode 实体更新转换为 $set 的方式
+type UpdateMode int
+
+const (
+	// UpdateStruct 直接使用实体作为 $set 内容 嵌套结构体整体替换 未声明 omitempty 的零值字段同样写入
+	UpdateStruct UpdateMode = iota
+	// UpdatePartial 只写入非零值字段与已设置的指针字段 嵌套结构体展开为点号路径
+	UpdatePartial
+)
+
+// WithUpdateMode 返回使用指定实体更新模式的 Mapper 副本 覆盖 MongoConfig.UpdateMode
+func (b BaseMapper[T]) WithUpdateMode(mode UpdateMode) BaseMapper[T] {
+	b.updateMode = &mode
+	return b
+}
+
+// WithFieldMask 返回 Mapper 副本 实体更新只写入指定路径 零值与 nil 同样写入 优先于更新模式
+// 路径使用 bson 字段名 嵌套字段使用点号路径 如 status、address.city
+func (b BaseMapper[T]) WithFieldMask(paths ...string) BaseMapper[T] {
+	b.fieldMask = append(slices.Clip(b.fieldMask), paths...)
+	return b
+}
+
+// update 按字段掩码或实体更新模式生成更新文档
+func (b BaseMapper[T]) update(update *T) (bson.M, error) {
+	mongoLock.RLock()
+	mode, unsetNull := updateMode, unsetNullOptionals
+	mongoLock.RUnlock()
+	if b.updateMode != nil {
+		mode = *b.updateMode
+	}
+	var set, unset bson.D
+	switch {
+	case len(b.fieldMask) > 0:
+		var err error
+		if set, unset, err = maskedUpdate(update, jsonStructTags(), b.fieldMask, unsetNull); err != nil {
+			return nil, err
+		}
+	case mode == UpdatePartial:
+		set, unset = flatten(update, jsonStructTags(), nil, unsetNull)
+		set = slices.DeleteFunc(set, func(e bson.E) bool {
+			return e.Key == "_id"
+		})
+	default:
+		return bson.M{"$set": update}, nil
+	}
+	document := updateDocument(set, unset)
+	if document == nil {
+		return nil, ErrEmptyUpdate
+	}
+	return document, nil
+}
+
+// maskedUpdate 按字段掩码从实体中取值 路径中的 nil 指针写入 null
+// 未设置或为 null 的 Optional 写入 null unsetNull 为 true 时使用 $unset
+func maskedUpdate(update any, jsonTags bool, paths []string, unsetNull bool) (set, unset bson.D, err error) {
+	value := reflect.ValueOf(update)
+	set = make(bson.D, 0, len(paths))
+	for _, path := range paths {
+		if path == "_id" {
+			return nil, nil, fmt.Errorf("%w: _id cannot be updated", ErrInvalidFieldMask)
+		}
+		field, found := lookupPath(value, strings.Split(path, "."), jsonTags)
+		if !found {
+			return nil, nil, fmt.Errorf("%w: unknown path %s", ErrInvalidFieldMask, path)
+		}
+		var fieldValue any
+		if field.IsValid() && field.CanInterface() {
+			fieldValue = field.Interface()
+		}
+		if state, inner, ok := optionalOf(field); ok {
+			if state == optionalValue {
+				fieldValue = inner
+			} else if unsetNull {
+				unset = append(unset, bson.E{Key: path, Value: ""})
+				continue
+			} else {
+				fieldValue = nil
+			}
+		}
+		set = append(set, bson.E{Key: path, Value: fieldValue})
+	}
+	return set, unset, nil
+}
+
+// lookupPath 按 bson 字段名查找路径对应的值 路径上的 nil 指针返回无效值
+func lookupPath(value reflect.Value, path []string, jsonTags bool) (reflect.Value, bool) {
+	if len(path) == 0 {
+		return value, true
+	}
+	t := value.Type()
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return reflect.Value{}, false
+	}
+	for value.IsValid() && value.Kind() == reflect.Ptr {
+		if value.IsNil() {
+			value = reflect.Value{}
+			break
+		}
+		value = value.Elem()
+	}
+	for i := 0; i < t.NumField(); i++ {
+		info, ok := parseStructField(t.Field(i), jsonTags)
+		if !ok {
+			continue
+		}
+		field := reflect.Value{}
+		if value.IsValid() {
+			field = value.Field(i)
+		}
+		if info.inline {
+			if found, ok := lookupPath(fieldOrZero(field, t.Field(i).Type), path, jsonTags); ok {
+				return found, true
+			}
+			continue
+		}
+		if info.name == path[0] {
+			if len(path) == 1 {
+				return field, true
+			}
+			return lookupPath(fieldOrZero(field, t.Field(i).Type), path[1:], jsonTags)
+		}
+	}
+	return reflect.Value{}, false
+}
+
+// fieldOrZero 字段所在结构体为 nil 时使用类型的 nil 指针继续查找 以便区分未知路径与 nil 值
+func fieldOrZero(field reflect.Value, t reflect.Type) reflect.Value {
+	if field.IsValid() {
+		return field
+	}
+	return reflect.Zero(reflect.PointerTo(t))
+}
@@ -0,0 +1,62 @@
+package test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/golang-acexy/starter-mongo/mongostarter"
+	"go.mongodb.org/mongo-driver/v2/mongo/readconcern"
+	"go.mongodb.org/mongo-driver/v2/mongo/readpref"
+	"go.mongodb.org/mongo-driver/v2/mongo/writeconcern"
+)
+
+type UnacknowledgedLog struct {
+	ID       string `bson:"_id,omitempty"`
+	Hostname string `bson:"hostname"`
+}
+
+func (UnacknowledgedLog) CollectionName() string {
+	return testCollection
+}
+
+func (UnacknowledgedLog) ReadWriteOptions() mongostarter.ReadWriteOptions {
+	return mongostarter.ReadWriteOptions{
+		ReadPreference: readpref.SecondaryPreferred(),
+		WriteConcern:   writeconcern.Unacknowledged(),
+	}
+}
+
+type UnacknowledgedLogMapper struct {
+	mongostarter.BaseMapper[UnacknowledgedLog]
+}
+
+func TestReadWriteOptions(t *testing.T) {
+	resetCollection(t)
+	var unacknowledged UnacknowledgedLogMapper
+
+	if _, err := unacknowledged.Insert(&UnacknowledgedLog{Hostname: "model"}); !errors.Is(err, mongostarter.ErrNotAcknowledged) {
+		t.Fatalf("expected model write concern to apply, got %v", err)
+	}
+
+	majority := unacknowledged.WithReadWrite(mongostarter.ReadWriteOptions{
+		ReadConcern:  readconcern.Majority(),
+		WriteConcern: writeconcern.Majority(),
+	})
+	if _, err := majority.Insert(&UnacknowledgedLog{Hostname: "majority"}); err != nil {
+		t.Fatalf("expected mapper write concern to override the model, got %v", err)
+	}
+	var logs []*UnacknowledgedLog
+	if err := majority.SelectByCond(&UnacknowledgedLog{Hostname: "majority"}, nil, &logs); err != nil {
+		t.Fatal(err)
+	}
+	if len(logs) != 1 {
+		t.Fatalf("expected 1 document, got %d", len(logs))
+	}
+
+	ctx := mongostarter.WithReadWriteOptions(t.Context(), mongostarter.ReadWriteOptions{
+		WriteConcern: writeconcern.Unacknowledged(),
+	})
+	if _, err := majority.WithContext(ctx).Insert(&UnacknowledgedLog{Hostname: "context"}); !errors.Is(err, mongostarter.ErrNotAcknowledged) {
+		t.Fatalf("expected context write concern to override the mapper, got %v", err)
+	}
+}
@@ -0,0 +1,83 @@
+package test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/golang-acexy/starter-mongo/mongostarter"
+)
+
+type ConditionLog struct {
+	ID       string `bson:"_id,omitempty"`
+	Hostname string `bson:"hostname"`
+	PID      int    `bson:"pid"`
+	Active   *bool  `bson:"active"`
+	Meta     struct {
+		Region string `bson:"region"`
+		Zone   string `bson:"zone"`
+	} `bson:"meta"`
+}
+
+func (ConditionLog) CollectionName() string {
+	return testCollection
+}
+
+type ConditionLogMapper struct {
+	mongostarter.BaseMapper[ConditionLog]
+}
+
+func TestNonZeroCondition(t *testing.T) {
+	resetCollection(t)
+	active, inactive := true, false
+	var conditionMapper ConditionLogMapper
+	for _, log := range []*ConditionLog{
+		{Hostname: "api", PID: 0, Active: &active},
+		{Hostname: "api", PID: 10, Active: &inactive},
+		{Hostname: "worker", PID: 20, Active: &inactive},
+	} {
+		log.Meta.Region = "eu"
+		log.Meta.Zone = log.Hostname
+		if _, err := conditionMapper.Insert(log); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	count, err := conditionMapper.CountByCond(&ConditionLog{Hostname: "api"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if count != 0 {
+		t.Fatalf("struct mode should match zero-valued fields, got %d", count)
+	}
+
+	nonZero := conditionMapper.WithConditionMode(mongostarter.ConditionNonZero)
+	count, err = nonZero.CountByCond(&ConditionLog{Hostname: "api"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if count != 2 {
+		t.Fatalf("expected 2 documents for hostname, got %d", count)
+	}
+
+	condition := &ConditionLog{Active: &inactive}
+	condition.Meta.Region = "eu"
+	count, err = nonZero.CountByCond(condition)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if count != 2 {
+		t.Fatalf("expected pointer and nested fields to match, got %d", count)
+	}
+
+	count, err = nonZero.MatchZero("pid").CountByCond(&ConditionLog{Hostname: "api"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if count != 1 {
+		t.Fatalf("expected explicit zero pid to match 1 document, got %d", count)
+	}
+
+	if _, err = nonZero.DeleteByCond(&ConditionLog{}); !errors.Is(err, mongostarter.ErrEmptyCondition) {
+		t.Fatalf("expected ErrEmptyCondition, got %v", err)
+	}
+}
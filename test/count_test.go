@@ -0,0 +1,66 @@
+package test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/golang-acexy/starter-mongo/mongostarter"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+func TestPageCountStrategies(t *testing.T) {
+	resetCollection(t)
+	var profiles ProfileMapper
+	for _, hostname := range []string{"api", "web", "worker", "batch", "cron"} {
+		if _, err := profiles.Insert(&Profile{Hostname: hostname, Visits: 1}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	page := func(filter bson.M, query mongostarter.PageQuery) (int64, mongostarter.PageCount) {
+		t.Helper()
+		var result []*Profile
+		query.PageNumber, query.PageSize = 1, 2
+		count, err := profiles.SelectPage(filter, query, &result)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(result) != 2 {
+			t.Fatalf("expected a full page, got %d", len(result))
+		}
+		return count.Total, count
+	}
+
+	if total, count := page(bson.M{}, mongostarter.PageQuery{CountStrategy: mongostarter.CountEstimated}); total != 5 || count.Strategy != mongostarter.CountEstimated {
+		t.Fatalf("unexpected estimated count: %d %+v", total, count)
+	}
+	if _, count := page(bson.M{"visits": 1}, mongostarter.PageQuery{CountStrategy: mongostarter.CountEstimated}); count.Strategy != mongostarter.CountExact {
+		t.Fatalf("expected filtered estimate to fall back to exact count: %+v", count)
+	}
+	if total, count := page(bson.M{}, mongostarter.PageQuery{CountStrategy: mongostarter.CountCapped, CountLimit: 3}); total != 3 || !count.Capped || count.String() != "3+" {
+		t.Fatalf("unexpected capped count: %d %+v", total, count)
+	}
+	if total, count := page(bson.M{}, mongostarter.PageQuery{CountStrategy: mongostarter.CountSkip}); total != -1 || count.String() != "unknown" {
+		t.Fatalf("unexpected skipped count: %d %+v", total, count)
+	}
+
+	cached := mongostarter.PageQuery{CountStrategy: mongostarter.CountCached}
+	if total, count := page(bson.M{"visits": 1}, cached); total != 5 || count.Cached {
+		t.Fatalf("unexpected first cached count: %d %+v", total, count)
+	}
+	if _, err := profiles.Insert(&Profile{Hostname: "extra", Visits: 1}); err != nil {
+		t.Fatal(err)
+	}
+	if total, count := page(bson.M{"visits": 1}, cached); total != 5 || !count.Cached {
+		t.Fatalf("expected stale total from cache: %d %+v", total, count)
+	}
+
+	var result []*Profile
+	total, err := profiles.SelectPageByBSON(bson.M{"visits": 1}, mongostarter.PageQuery{PageNumber: 1, PageSize: 2, CountStrategy: mongostarter.CountCached}, &result)
+	if err != nil || total != 5 {
+		t.Fatalf("expected SelectPageByBSON to share the cached total: %d %v", total, err)
+	}
+	_, err = profiles.SelectPageByBSON(bson.M{}, mongostarter.PageQuery{PageNumber: 1, PageSize: 2, CountStrategy: mongostarter.CountCapped}, &result)
+	if !errors.Is(err, mongostarter.ErrInvalidPage) {
+		t.Fatalf("expected ErrInvalidPage for capped count without limit, got %v", err)
+	}
+}
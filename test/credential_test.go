@@ -0,0 +1,113 @@
+package test
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang-acexy/starter-mongo/mongostarter"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+const rotatedUser = "it_rotated"
+
+// waitForRotation 等待凭据轮换替换当前客户端
+func waitForRotation(t *testing.T, old *mongo.Client) *mongo.Client {
+	t.Helper()
+	deadline := time.Now().Add(10 * time.Second)
+	for time.Now().Before(deadline) {
+		if client := mongostarter.RawMongoClient(); client != nil && client != old {
+			return client
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+	t.Fatal("credential rotation did not replace the client")
+	return nil
+}
+
+func authenticatedUser(t *testing.T) string {
+	t.Helper()
+	var status struct {
+		AuthInfo struct {
+			AuthenticatedUsers []struct {
+				User string `bson:"user"`
+			} `bson:"authenticatedUsers"`
+		} `bson:"authInfo"`
+	}
+	if err := mongostarter.RawDatabase("admin").RunCommand(t.Context(), bson.D{{Key: "connectionStatus", Value: 1}}).Decode(&status); err != nil {
+		t.Fatal(err)
+	}
+	if len(status.AuthInfo.AuthenticatedUsers) != 1 {
+		t.Fatalf("unexpected authenticated users: %+v", status.AuthInfo.AuthenticatedUsers)
+	}
+	return status.AuthInfo.AuthenticatedUsers[0].User
+}
+
+func TestCredentialRotation(t *testing.T) {
+	resetCollection(t)
+	id := insertLog(t, "rotation", 1)
+	admin := mongostarter.RawDatabase("admin")
+	_ = admin.RunCommand(t.Context(), bson.D{{Key: "dropUser", Value: rotatedUser}}).Err()
+	if err := admin.RunCommand(t.Context(), bson.D{
+		{Key: "createUser", Value: rotatedUser},
+		{Key: "pwd", Value: "it-rotated-pass"},
+		{Key: "roles", Value: bson.A{bson.D{{Key: "role", Value: "root"}, {Key: "db", Value: "admin"}}}},
+	}).Err(); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		old := mongostarter.RawMongoClient()
+		credential.Store(&initialCredential)
+		waitForRotation(t, old)
+		if err := mongostarter.RawDatabase("admin").RunCommand(context.Background(), bson.D{{Key: "dropUser", Value: rotatedUser}}).Err(); err != nil {
+			t.Error(err)
+		}
+	})
+	for len(connectionClosed) > 0 {
+		<-connectionClosed
+	}
+
+	// 轮换期间 Mapper 调用持续进行 不应出现错误
+	done := make(chan struct{})
+	var wg sync.WaitGroup
+	var failures []error
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for {
+			select {
+			case <-done:
+				return
+			default:
+			}
+			var log StartupLog
+			if err := mapper.SelectOneByBSON(bson.M{"_id": id}, &log); err != nil {
+				failures = append(failures, err)
+			}
+		}
+	}()
+
+	old := mongostarter.RawMongoClient()
+	credential.Store(&mongostarter.Credential{Username: rotatedUser, Password: "it-rotated-pass"})
+	waitForRotation(t, old)
+	close(done)
+	wg.Wait()
+	if len(failures) > 0 {
+		t.Fatalf("expected mapper calls to keep working during rotation, got %v", failures)
+	}
+	if user := authenticatedUser(t); user != rotatedUser {
+		t.Fatalf("expected the new client to authenticate as %s, got %s", rotatedUser, user)
+	}
+	var log StartupLog
+	if err := mapper.SelectOneByBSON(bson.M{"_id": id}, &log); err != nil || log.Hostname != "rotation" {
+		t.Fatalf("expected the rotated client to read the document, got %+v %v", log, err)
+	}
+
+	select {
+	case <-connectionClosed:
+	case <-time.After(10 * time.Second):
+		t.Fatal("expected the previous client to close its connections after draining")
+	}
+}
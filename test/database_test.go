@@ -0,0 +1,67 @@
+package test
+
+import (
+	"testing"
+
+	"github.com/golang-acexy/starter-mongo/mongostarter"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+const (
+	reportingDatabase = "starter_mongo_reporting_test"
+	archiveDatabase   = "starter_mongo_archive_test"
+)
+
+type ReportingLog struct {
+	ID       string `bson:"_id,omitempty"`
+	Hostname string `bson:"hostname"`
+}
+
+func (ReportingLog) CollectionName() string {
+	return testCollection
+}
+
+func (ReportingLog) DatabaseName() string {
+	return reportingDatabase
+}
+
+type ReportingLogMapper struct {
+	mongostarter.BaseMapper[ReportingLog]
+}
+
+func TestMapperDatabase(t *testing.T) {
+	resetCollection(t)
+	t.Cleanup(func() {
+		_ = mongostarter.RawDatabase(reportingDatabase).Drop(t.Context())
+		_ = mongostarter.RawDatabase(archiveDatabase).Drop(t.Context())
+	})
+	var reporting ReportingLogMapper
+
+	if _, err := reporting.Insert(&ReportingLog{Hostname: "reporting"}); err != nil {
+		t.Fatal(err)
+	}
+	archive := reporting.WithDatabase(archiveDatabase)
+	if _, err := archive.Insert(&ReportingLog{Hostname: "archive"}); err != nil {
+		t.Fatal(err)
+	}
+
+	for database, hostname := range map[string]string{reportingDatabase: "reporting", archiveDatabase: "archive"} {
+		count, err := mongostarter.RawCollection(testCollection, database).CountDocuments(t.Context(), bson.M{"hostname": hostname})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if count != 1 {
+			t.Fatalf("expected 1 document in %s, got %d", database, count)
+		}
+	}
+	count, err := mapper.CountByBSON(bson.M{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if count != 0 {
+		t.Fatalf("expected default database to stay empty, got %d", count)
+	}
+	if name := archive.Collection().Database().Name(); name != archiveDatabase {
+		t.Fatalf("expected collection in %s, got %s", archiveDatabase, name)
+	}
+}
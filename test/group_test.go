@@ -0,0 +1,76 @@
+package test
+
+import (
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/golang-acexy/starter-mongo/mongostarter"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+func TestDistinctAndCountGroupBy(t *testing.T) {
+	resetCollection(t)
+	var profiles ProfileMapper
+	for _, profile := range []*Profile{
+		{Hostname: "api", Visits: 1, Address: ProfileAddress{City: "Berlin"}},
+		{Hostname: "api", Visits: 2, Address: ProfileAddress{City: "Paris"}},
+		{Hostname: "web", Visits: 2, Address: ProfileAddress{City: "Berlin"}},
+		{Hostname: "worker", Visits: 3, Address: ProfileAddress{City: "Berlin"}},
+	} {
+		if _, err := profiles.Insert(profile); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	cities, err := mongostarter.Distinct[string](profiles.BaseMapper, "address.city", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	slices.Sort(cities)
+	if !slices.Equal(cities, []string{"Berlin", "Paris"}) {
+		t.Fatalf("unexpected distinct cities: %v", cities)
+	}
+	hostnames, err := mongostarter.Distinct[string](profiles.BaseMapper, "hostname", &Profile{Visits: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(hostnames) != 2 {
+		t.Fatalf("expected typed condition to filter distinct values: %v", hostnames)
+	}
+
+	counts, err := profiles.CountGroupBy(nil, mongostarter.GroupQuery{Fields: []string{"hostname"}, Limit: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(counts) != 2 || counts[0].Value() != "api" || counts[0].Count != 2 || counts[1].Value() != "web" {
+		t.Fatalf("unexpected top groups: %+v %+v", counts[0], counts[1])
+	}
+
+	counts, err = profiles.CountGroupBy(bson.M{"address.city": "Berlin"}, mongostarter.GroupQuery{
+		Fields: []string{"address.city", "visits"},
+		Sort:   mongostarter.GroupSortValueDesc,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(counts) != 3 || counts[0].Values[1] != int64(3) && counts[0].Values[1] != int32(3) {
+		t.Fatalf("unexpected multi-field groups: %+v", counts)
+	}
+	if byHost := mustGroupMap(t, profiles, "hostname"); byHost["worker"] != 1 || byHost["api"] != 2 {
+		t.Fatalf("unexpected group map: %v", byHost)
+	}
+
+	if _, err = profiles.CountGroupBy(nil, mongostarter.GroupQuery{}); !errors.Is(err, mongostarter.ErrInvalidGroupQuery) {
+		t.Fatalf("expected ErrInvalidGroupQuery, got %v", err)
+	}
+}
+
+func mustGroupMap(t *testing.T, profiles ProfileMapper, field string) map[any]int64 {
+	t.Helper()
+	counts, err := profiles.CountGroupBy(nil, mongostarter.GroupQuery{Fields: []string{field}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return counts.Map()
+}
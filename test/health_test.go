@@ -0,0 +1,48 @@
+package test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/golang-acexy/starter-mongo/mongostarter"
+)
+
+func TestHealth(t *testing.T) {
+	status, err := mongostarter.Health(t.Context())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !status.Healthy || status.PingLatency <= 0 {
+		t.Fatalf("unexpected health status: %+v", status)
+	}
+	if status.ServerVersion == "" {
+		t.Fatal("expected server version")
+	}
+	if status.TopologyKind == "" {
+		t.Fatal("expected topology kind")
+	}
+}
+
+func TestHealthHandlerCache(t *testing.T) {
+	handler := mongostarter.NewHealthHandler(mongostarter.HealthHandlerConfig{CacheInterval: time.Minute})
+	probe := func() mongostarter.HealthStatus {
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+		if recorder.Code != http.StatusOK {
+			t.Fatalf("unexpected status: %d %s", recorder.Code, recorder.Body.String())
+		}
+		var status mongostarter.HealthStatus
+		if err := json.Unmarshal(recorder.Body.Bytes(), &status); err != nil {
+			t.Fatal(err)
+		}
+		return status
+	}
+	first := probe()
+	second := probe()
+	if !first.CheckedAt.Equal(second.CheckedAt) {
+		t.Fatal("expected cached probe result")
+	}
+}
@@ -0,0 +1,85 @@
+package test
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/golang-acexy/starter-mongo/mongostarter"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+const indexCollection = "starter_mongo_index_test"
+
+type IndexedLog struct {
+	ID        string    `bson:"_id,omitempty"`
+	Hostname  string    `bson:"hostname" index:"host_pid,unique"`
+	PID       int       `bson:"pid" index:"host_pid,desc"`
+	Message   string    `bson:"message" index:",text"`
+	CreatedAt time.Time `bson:"createdAt" index:",ttl=24h"`
+	Level     string    `bson:"level"`
+}
+
+func (IndexedLog) CollectionName() string {
+	return indexCollection
+}
+
+func (IndexedLog) Indexes() []mongostarter.Index {
+	return []mongostarter.Index{{
+		Keys:          bson.D{{Key: "level", Value: 1}},
+		PartialFilter: bson.D{{Key: "level", Value: bson.D{{Key: "$exists", Value: true}}}},
+		Collation:     &options.Collation{Locale: "en", Strength: 2},
+	}}
+}
+
+func TestEnsureIndexes(t *testing.T) {
+	collection := mongostarter.RawCollection(indexCollection)
+	t.Cleanup(func() {
+		_ = collection.Drop(t.Context())
+	})
+	config := mongostarter.IndexSyncConfig{Models: []mongostarter.Model{IndexedLog{}}}
+
+	reports, err := mongostarter.EnsureIndexes(t.Context(), config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	created := reports[0].Created
+	for _, name := range []string{"host_pid", "message_text", "createdAt_1", "level_1"} {
+		if !slices.Contains(created, name) {
+			t.Fatalf("index %s was not created: %v", name, created)
+		}
+	}
+
+	reports, err = mongostarter.EnsureIndexes(t.Context(), config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(reports[0].Created) > 0 || len(reports[0].Different) > 0 {
+		t.Fatalf("expected indexes in sync: %+v", reports[0])
+	}
+
+	if _, err = collection.Indexes().CreateOne(t.Context(), mongo.IndexModel{
+		Keys:    bson.D{{Key: "pid", Value: 1}},
+		Options: options.Index().SetName("manual_pid"),
+	}); err != nil {
+		t.Fatal(err)
+	}
+	reports, err = mongostarter.EnsureIndexes(t.Context(), config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(reports[0].Undeclared, []string{"manual_pid"}) || len(reports[0].Dropped) > 0 {
+		t.Fatalf("expected undeclared index to be kept: %+v", reports[0])
+	}
+
+	config.Prune = true
+	reports, err = mongostarter.EnsureIndexes(t.Context(), config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(reports[0].Dropped, []string{"manual_pid"}) {
+		t.Fatalf("expected undeclared index to be dropped: %+v", reports[0])
+	}
+}
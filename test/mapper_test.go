@@ -330,6 +330,38 @@ func TestQueryVariantsAndPagination(t *testing.T) {
 	}
 }
 
+func TestNilMultipleResult(t *testing.T) {
+	resetCollection(t)
+	id := insertLog(t, "nil-result", 1)
+	page := mongostarter.PageQuery{PageNumber: 1, PageSize: 1}
+	calls := map[string]func() error{
+		"SelectByIDs":       func() error { return mapper.SelectByIDs([]any{id}, nil) },
+		"SelectByCond":      func() error { return mapper.SelectByCond(&StartupLog{Hostname: "nil-result"}, nil, nil) },
+		"SelectByBSON":      func() error { return mapper.SelectByBSON(bson.M{}, nil, nil) },
+		"SelectWithOptions": func() error { return mapper.SelectWithOptions(bson.M{}, nil) },
+		"SelectPageByBSON": func() error {
+			_, err := mapper.SelectPageByBSON(bson.M{}, page, nil)
+			return err
+		},
+		"SelectPageWithOptions": func() error {
+			_, err := mapper.SelectPageWithOptions(bson.M{}, page, nil)
+			return err
+		},
+		"SelectAs": func() error {
+			return mongostarter.SelectAs[StartupLog, StartupLog](mapper.BaseMapper, nil, nil, nil)
+		},
+		"SelectPageAs": func() error {
+			_, err := mongostarter.SelectPageAs[StartupLog, StartupLog](mapper.BaseMapper, nil, page, nil)
+			return err
+		},
+	}
+	for name, call := range calls {
+		if err := call(); err == nil {
+			t.Fatalf("%s: expected an error for a nil result", name)
+		}
+	}
+}
+
 func TestListAndCountVariants(t *testing.T) {
 	resetCollection(t)
 	insertLog(t, "count-a", 1)
@@ -0,0 +1,37 @@
+package test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/golang-acexy/starter-mongo/mongostarter"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+func TestMetricsHandler(t *testing.T) {
+	resetCollection(t)
+	insertLog(t, "metrics", 1)
+	if _, err := mapper.CountByBSON(bson.M{}); err != nil {
+		t.Fatal(err)
+	}
+
+	recorder := httptest.NewRecorder()
+	mongostarter.MetricsHandler().ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("unexpected status: %d", recorder.Code)
+	}
+	body := recorder.Body.String()
+	for _, expected := range []string{
+		`mongo_command_duration_seconds_count{database="local",collection="` + mongostarter.ApplyNaming(testCollection) + `",command="insert"}`,
+		`mongo_command_errors_total{database="local",collection="` + mongostarter.ApplyNaming(testCollection) + `",command="insert"} 0`,
+		"# TYPE mongo_pool_connections_checked_out gauge",
+		"mongo_pool_connections_idle{address=",
+		"mongo_pool_wait_duration_seconds_count{address=",
+	} {
+		if !strings.Contains(body, expected) {
+			t.Fatalf("expected metrics output to contain %q:\n%s", expected, body)
+		}
+	}
+}
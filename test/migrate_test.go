@@ -0,0 +1,99 @@
+package test
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/golang-acexy/starter-mongo/mongostarter"
+	"github.com/golang-acexy/starter-mongo/mongostarter/migrate"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+const migrationCollection = "starter_mongo_migrations_test"
+
+var migrationRuns atomic.Int32
+
+func init() {
+	migrate.Register(
+		migrate.Migration{
+			ID:          "20261019_001_seed",
+			Description: "seed a startup log",
+			Up: func(ctx context.Context, db *mongo.Database) error {
+				migrationRuns.Add(1)
+				_, err := db.Collection(mongostarter.ApplyNaming(testCollection)).InsertOne(ctx, bson.M{"hostname": "migrated"})
+				return err
+			},
+			Down: func(ctx context.Context, db *mongo.Database) error {
+				_, err := db.Collection(mongostarter.ApplyNaming(testCollection)).DeleteMany(ctx, bson.M{"hostname": "migrated"})
+				return err
+			},
+		},
+		migrate.Migration{
+			ID: "20261019_002_rename",
+			Up: func(ctx context.Context, db *mongo.Database) error {
+				migrationRuns.Add(1)
+				_, err := db.Collection(mongostarter.ApplyNaming(testCollection)).UpdateMany(ctx, bson.M{"hostname": "migrated"}, bson.M{"$set": bson.M{"pid": 1}})
+				return err
+			},
+			Down: func(ctx context.Context, db *mongo.Database) error {
+				_, err := db.Collection(mongostarter.ApplyNaming(testCollection)).UpdateMany(ctx, bson.M{"hostname": "migrated"}, bson.M{"$unset": bson.M{"pid": ""}})
+				return err
+			},
+		},
+	)
+}
+
+func TestMigrate(t *testing.T) {
+	config := migrate.Config{Collection: migrationCollection, LockCollection: migrationCollection + "_lock"}
+	t.Cleanup(func() {
+		_ = mongostarter.RawCollection(migrationCollection).Drop(context.Background())
+		_ = mongostarter.RawCollection(migrationCollection + "_lock").Drop(context.Background())
+		_, _ = mongostarter.RawCollection(testCollection).DeleteMany(context.Background(), bson.M{"hostname": "migrated"})
+	})
+
+	dryRun := config
+	dryRun.DryRun = true
+	result, err := migrate.Run(t.Context(), dryRun)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(result.Applied, []string{"20261019_001_seed", "20261019_002_rename"}) || migrationRuns.Load() != 0 {
+		t.Fatalf("unexpected dry run: %+v", result)
+	}
+
+	var wg sync.WaitGroup
+	for range 3 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := migrate.Run(t.Context(), config); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+	if migrationRuns.Load() != 2 {
+		t.Fatalf("expected each migration to run once, got %d runs", migrationRuns.Load())
+	}
+
+	target := config
+	target.Target = "20261019_001_seed"
+	result, err = migrate.Run(t.Context(), target)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(result.RolledBack, []string{"20261019_002_rename"}) || len(result.Applied) > 0 {
+		t.Fatalf("unexpected rollback: %+v", result)
+	}
+	count, err := mongostarter.RawCollection(migrationCollection).CountDocuments(t.Context(), bson.M{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if count != 1 {
+		t.Fatalf("expected one migration record, got %d", count)
+	}
+}
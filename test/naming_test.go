@@ -0,0 +1,43 @@
+package test
+
+import (
+	"testing"
+
+	"github.com/golang-acexy/starter-mongo/mongostarter"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+type AutoNamedLog struct {
+	mongostarter.AutoNamed `bson:"-"`
+	ID                     string `bson:"_id,omitempty"`
+	Hostname               string `bson:"hostname"`
+}
+
+type AutoNamedLogMapper struct {
+	mongostarter.BaseMapper[AutoNamedLog]
+}
+
+func TestNamingStrategy(t *testing.T) {
+	if name := mongostarter.RawCollection(testCollection).Name(); name != "it_"+testCollection {
+		t.Fatalf("expected prefixed raw collection, got %s", name)
+	}
+	if name := mapper.Collection().Name(); name != "it_"+testCollection {
+		t.Fatalf("expected prefixed mapper collection, got %s", name)
+	}
+
+	physical := mongostarter.RawDatabase().Collection("it_auto_named_log")
+	t.Cleanup(func() {
+		_ = physical.Drop(t.Context())
+	})
+	var autoNamed AutoNamedLogMapper
+	if _, err := autoNamed.Insert(&AutoNamedLog{Hostname: "auto"}); err != nil {
+		t.Fatal(err)
+	}
+	count, err := physical.CountDocuments(t.Context(), bson.M{"hostname": "auto"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if count != 1 {
+		t.Fatalf("expected collection derived from struct type, got %d documents", count)
+	}
+}
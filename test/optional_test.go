@@ -0,0 +1,154 @@
+package test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/golang-acexy/starter-mongo/mongostarter"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+type Contact struct {
+	ID       string                        `bson:"_id,omitempty"`
+	Hostname string                        `bson:"hostname"`
+	Email    mongostarter.Optional[string] `bson:"email,omitempty" json:"email,omitzero"`
+	Retries  mongostarter.Optional[int]    `bson:"retries,omitempty" json:"retries,omitzero"`
+}
+
+func (Contact) CollectionName() string {
+	return testCollection
+}
+
+type ContactMapper struct {
+	mongostarter.BaseMapper[Contact]
+}
+
+func TestOptionalUpdate(t *testing.T) {
+	resetCollection(t)
+	var contacts ContactMapper
+	id, err := contacts.Insert(&Contact{
+		Hostname: "api",
+		Email:    mongostarter.Some("ops@example.com"),
+		Retries:  mongostarter.Some(0),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	load := func() Contact {
+		t.Helper()
+		var contact Contact
+		if err := contacts.SelectByID(id, &contact); err != nil {
+			t.Fatal(err)
+		}
+		return contact
+	}
+	if retries, ok := load().Retries.Get(); !ok || retries != 0 {
+		t.Fatalf("expected zero retries to be stored as a value, got %v %v", retries, ok)
+	}
+
+	partial := contacts.WithUpdateMode(mongostarter.UpdatePartial)
+	if _, err = partial.UpdateByID(&Contact{Email: mongostarter.Null[string]()}, id); err != nil {
+		t.Fatal(err)
+	}
+	contact := load()
+	if !contact.Email.IsNull() || contact.Hostname != "api" || contact.Retries.OrElse(-1) != 0 {
+		t.Fatalf("unexpected contact after null update: %+v", contact)
+	}
+
+	if _, err = partial.UpdateByID(&Contact{Retries: mongostarter.Some(3)}, id); err != nil {
+		t.Fatal(err)
+	}
+	contact = load()
+	if contact.Retries.OrElse(0) != 3 || !contact.Email.IsNull() {
+		t.Fatalf("unexpected contact after value update: %+v", contact)
+	}
+
+	var matched []*Contact
+	err = contacts.WithConditionMode(mongostarter.ConditionNonZero).SelectByCond(&Contact{Email: mongostarter.Null[string]()}, nil, &matched)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(matched) != 1 {
+		t.Fatalf("expected null optional to match one document, got %d", len(matched))
+	}
+}
+
+func TestOptionalMarshal(t *testing.T) {
+	data, err := json.Marshal(Contact{Hostname: "api", Email: mongostarter.Null[string]()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var contact Contact
+	if err = json.Unmarshal(data, &contact); err != nil {
+		t.Fatal(err)
+	}
+	if !contact.Email.IsNull() || contact.Retries.IsSet() {
+		t.Fatalf("unexpected json round trip: %s %+v", data, contact)
+	}
+
+	raw, err := bson.Marshal(Contact{Hostname: "api", Retries: mongostarter.Some(2)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = bson.Raw(raw).LookupErr("email"); err == nil {
+		t.Fatal("expected unset optional to be omitted")
+	}
+	if value := bson.Raw(raw).Lookup("retries"); value.Type != bson.TypeInt64 && value.Type != bson.TypeInt32 {
+		t.Fatalf("expected retries to be stored as an integer, got %s", value.Type)
+	}
+}
+
+type ContactRef struct {
+	ID       string                         `bson:"_id,omitempty"`
+	Hostname string                         `bson:"hostname,omitempty"`
+	Email    *mongostarter.Optional[string] `bson:"email,omitempty"`
+}
+
+func (ContactRef) CollectionName() string {
+	return testCollection
+}
+
+type ContactRefMapper struct {
+	mongostarter.BaseMapper[ContactRef]
+}
+
+func TestOptionalPointer(t *testing.T) {
+	resetCollection(t)
+	var contacts ContactRefMapper
+	email := mongostarter.Some("ops@example.com")
+	id, err := contacts.Insert(&ContactRef{Hostname: "api", Email: &email})
+	if err != nil {
+		t.Fatal(err)
+	}
+	load := func() ContactRef {
+		t.Helper()
+		var contact ContactRef
+		if err := contacts.SelectByID(id, &contact); err != nil {
+			t.Fatal(err)
+		}
+		return contact
+	}
+
+	var matched []*ContactRef
+	err = contacts.WithConditionMode(mongostarter.ConditionNonZero).SelectByCond(&ContactRef{Hostname: "api"}, nil, &matched)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(matched) != 1 {
+		t.Fatalf("expected nil optional pointer to be ignored in conditions, got %d matches", len(matched))
+	}
+
+	if _, err = contacts.WithUpdateMode(mongostarter.UpdatePartial).UpdateByID(&ContactRef{Hostname: "web"}, id); err != nil {
+		t.Fatal(err)
+	}
+	if contact := load(); contact.Hostname != "web" || contact.Email == nil || contact.Email.OrElse("") != "ops@example.com" {
+		t.Fatalf("expected nil optional pointer to be left unchanged: %+v", contact)
+	}
+
+	if _, err = contacts.WithFieldMask("email").UpdateByID(&ContactRef{}, id); err != nil {
+		t.Fatal(err)
+	}
+	if contact := load(); contact.Email != nil && contact.Email.IsSet() && !contact.Email.IsNull() {
+		t.Fatalf("expected masked nil optional pointer to clear the field: %+v", contact)
+	}
+}
@@ -0,0 +1,129 @@
+package test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang-acexy/starter-mongo/mongostarter"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+var logPartitioning = mongostarter.Partitioning{Prefix: "starter_mongo_partition_test"}
+
+type PartitionedLog struct {
+	ID        string    `bson:"_id,omitempty"`
+	Hostname  string    `bson:"hostname"`
+	CreatedAt time.Time `bson:"createdAt"`
+}
+
+func (PartitionedLog) CollectionName() string {
+	return logPartitioning.Collection(time.Now())
+}
+
+func (PartitionedLog) ResolveCollection(_ context.Context, entity *PartitionedLog) string {
+	if entity == nil || entity.CreatedAt.IsZero() {
+		return ""
+	}
+	return logPartitioning.Collection(entity.CreatedAt)
+}
+
+type PartitionedLogMapper struct {
+	mongostarter.BaseMapper[PartitionedLog]
+}
+
+func TestPartitionedCollections(t *testing.T) {
+	from := time.Date(2026, time.August, 15, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2026, time.October, 2, 0, 0, 0, 0, time.UTC)
+	collections := logPartitioning.Collections(from, to)
+	expected := []string{"starter_mongo_partition_test_202608", "starter_mongo_partition_test_202609", "starter_mongo_partition_test_202610"}
+	if len(collections) != len(expected) {
+		t.Fatalf("unexpected partitions: %v", collections)
+	}
+	for i := range expected {
+		if collections[i] != expected[i] {
+			t.Fatalf("unexpected partitions: %v", collections)
+		}
+	}
+	t.Cleanup(func() {
+		for _, collection := range collections {
+			_ = mongostarter.RawCollection(collection).Drop(t.Context())
+		}
+	})
+
+	var partitioned PartitionedLogMapper
+	logs := []*PartitionedLog{
+		{Hostname: "september", CreatedAt: time.Date(2026, time.September, 10, 0, 0, 0, 0, time.UTC)},
+		{Hostname: "august", CreatedAt: time.Date(2026, time.August, 20, 0, 0, 0, 0, time.UTC)},
+		{Hostname: "october", CreatedAt: time.Date(2026, time.October, 1, 0, 0, 0, 0, time.UTC)},
+		{Hostname: "august-late", CreatedAt: time.Date(2026, time.August, 30, 0, 0, 0, 0, time.UTC)},
+	}
+	ids, err := partitioned.InsertBatch(logs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ids) != len(logs) {
+		t.Fatalf("expected %d ids, got %v", len(logs), ids)
+	}
+	count, err := mongostarter.RawCollection(collections[0]).CountDocuments(t.Context(), bson.M{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if count != 2 {
+		t.Fatalf("expected 2 documents in %s, got %d", collections[0], count)
+	}
+
+	var merged []*PartitionedLog
+	query := mongostarter.AcrossQuery{OrderBy: mongostarter.NewOrderBy("createdAt", true), SpecifyColumns: []string{"hostname"}}
+	if err = partitioned.SelectAcross(collections, bson.M{}, query, &merged); err != nil {
+		t.Fatal(err)
+	}
+	order := []string{"october", "september", "august-late", "august"}
+	if len(merged) != len(order) {
+		t.Fatalf("expected %d documents, got %d", len(order), len(merged))
+	}
+	for i, hostname := range order {
+		if merged[i].Hostname != hostname {
+			t.Fatalf("unexpected merge order at %d: %s", i, merged[i].Hostname)
+		}
+		if !merged[i].CreatedAt.IsZero() {
+			t.Fatalf("expected injected sort column to be removed: %+v", merged[i])
+		}
+	}
+
+	query.Limit = 3
+	if err = partitioned.SelectAcross(collections, bson.M{}, query, &merged); err != nil {
+		t.Fatal(err)
+	}
+	if len(merged) != 3 || merged[2].Hostname != "august-late" {
+		t.Fatalf("unexpected limited merge: %+v", merged)
+	}
+	if err = partitioned.SelectAcross(collections, bson.M{}, query, nil); !errors.Is(err, mongostarter.ErrInvalidAcrossQuery) {
+		t.Fatalf("expected ErrInvalidAcrossQuery for a nil result, got %v", err)
+	}
+
+	total, err := partitioned.CountAcross(append(collections, "starter_mongo_partition_test_202611"), bson.M{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if total != 4 {
+		t.Fatalf("expected 4 documents, got %d", total)
+	}
+
+	ctx := mongostarter.WithCollectionName(t.Context(), collections[1])
+	count, err = partitioned.WithContext(ctx).CountByBSON(bson.M{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if count != 1 {
+		t.Fatalf("expected 1 document in %s, got %d", collections[1], count)
+	}
+	count, err = partitioned.WithContext(ctx).WithCollection(collections[0]).CountByBSON(bson.M{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if count != 2 {
+		t.Fatalf("expected explicit collection to take precedence, got %d", count)
+	}
+}
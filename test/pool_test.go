@@ -0,0 +1,35 @@
+package test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/golang-acexy/starter-mongo/mongostarter"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/event"
+)
+
+var connectionCreated = make(chan *event.PoolEvent, 1)
+var connectionClosed = make(chan *event.PoolEvent, 1)
+
+func TestPoolHooksOnConnectionCreated(t *testing.T) {
+	if _, err := mapper.CountByBSON(bson.M{}); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case evt := <-connectionCreated:
+		if evt.Type != event.ConnectionCreated || evt.Address == "" {
+			t.Fatalf("unexpected connection created event: %+v", evt)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("OnConnectionCreated was not called")
+	}
+
+	status, err := mongostarter.Health(t.Context())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(status.Pools) == 0 || status.Pools[0].Open <= 0 {
+		t.Fatalf("expected open connections in the pool stats: %+v", status.Pools)
+	}
+}
@@ -0,0 +1,61 @@
+package test
+
+import (
+	"testing"
+
+	"github.com/golang-acexy/starter-mongo/mongostarter"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+type ProfileSummary struct {
+	Hostname string `bson:"hostname"`
+	Address  struct {
+		City string `bson:"city"`
+	} `bson:"address"`
+}
+
+func TestSelectAs(t *testing.T) {
+	resetCollection(t)
+	var profiles ProfileMapper
+	note := "large"
+	for _, hostname := range []string{"api", "web", "worker"} {
+		if _, err := profiles.Insert(&Profile{Hostname: hostname, Visits: 1, Note: &note, Address: ProfileAddress{City: "Berlin", Street: "Main"}}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var summaries []*ProfileSummary
+	err := mongostarter.SelectAs(profiles.BaseMapper, &Profile{Hostname: "web"}, nil, &summaries)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(summaries) != 1 || summaries[0].Hostname != "web" || summaries[0].Address.City != "Berlin" {
+		t.Fatalf("unexpected summaries: %+v", summaries)
+	}
+
+	var page []*ProfileSummary
+	count, err := mongostarter.SelectPageAs(profiles.BaseMapper, bson.M{"visits": 1}, mongostarter.PageQuery{
+		PageNumber: 2,
+		PageSize:   2,
+		OrderBy:    mongostarter.NewOrderBy("hostname", false),
+	}, &page)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if count.Total != 3 || len(page) != 1 || page[0].Hostname != "worker" {
+		t.Fatalf("unexpected page: total=%d %+v", count.Total, page)
+	}
+
+	var trimmed []*Profile
+	if err = mongostarter.SelectAs(profiles.BaseMapper, nil, nil, &trimmed, "note", "address.street"); err != nil {
+		t.Fatal(err)
+	}
+	if len(trimmed) != 3 || trimmed[0].Note != nil || trimmed[0].Address.Street != "" || trimmed[0].Address.City != "Berlin" || trimmed[0].ID == "" {
+		t.Fatalf("exclusion projection did not hide fields: %+v", trimmed[0])
+	}
+
+	_, err = profiles.SelectPageByBSON(bson.M{}, mongostarter.PageQuery{PageNumber: 1, PageSize: 1, SpecifyColumns: []string{"hostname"}, ExcludeColumns: []string{"note"}}, &trimmed)
+	if err != mongostarter.ErrInvalidProjection {
+		t.Fatalf("expected ErrInvalidProjection, got %v", err)
+	}
+}
@@ -0,0 +1,121 @@
+package test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang-acexy/starter-mongo/mongostarter"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+const (
+	cappedCollection     = "starter_mongo_capped_test"
+	timeSeriesCollection = "starter_mongo_timeseries_test"
+)
+
+type CappedEvent struct {
+	ID      string `bson:"_id,omitempty"`
+	Message string `bson:"message"`
+}
+
+func (CappedEvent) CollectionName() string {
+	return cappedCollection
+}
+
+func (CappedEvent) CollectionOptions() mongostarter.CollectionOptions {
+	return mongostarter.CollectionOptions{
+		Capped:       true,
+		SizeInBytes:  1000,
+		MaxDocuments: 100,
+		Collation:    &options.Collation{Locale: "en", Strength: 2},
+	}
+}
+
+type Measurement struct {
+	Timestamp time.Time `bson:"timestamp"`
+	Sensor    string    `bson:"sensor"`
+	Value     float64   `bson:"value"`
+}
+
+func (Measurement) CollectionName() string {
+	return timeSeriesCollection
+}
+
+func (Measurement) CollectionOptions() mongostarter.CollectionOptions {
+	return mongostarter.CollectionOptions{
+		TimeSeries: &mongostarter.TimeSeriesOptions{
+			TimeField:   "timestamp",
+			MetaField:   "sensor",
+			Granularity: "minutes",
+		},
+		ExpireAfter: 24 * time.Hour,
+	}
+}
+
+type InvalidCapped struct{}
+
+func (InvalidCapped) CollectionName() string {
+	return "starter_mongo_invalid_capped_test"
+}
+
+func (InvalidCapped) CollectionOptions() mongostarter.CollectionOptions {
+	return mongostarter.CollectionOptions{Capped: true}
+}
+
+func TestProvisionCollections(t *testing.T) {
+	t.Cleanup(func() {
+		_ = mongostarter.RawCollection(cappedCollection).Drop(t.Context())
+		_ = mongostarter.RawCollection(timeSeriesCollection).Drop(t.Context())
+	})
+	config := mongostarter.ProvisionConfig{Models: []mongostarter.Model{CappedEvent{}, Measurement{}}, DryRun: true}
+
+	reports, err := mongostarter.ProvisionCollections(t.Context(), config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, report := range reports {
+		if !report.Missing || report.Created {
+			t.Fatalf("dry run should only report missing collections: %+v", report)
+		}
+	}
+
+	config.DryRun = false
+	reports, err = mongostarter.ProvisionCollections(t.Context(), config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, report := range reports {
+		if !report.Created {
+			t.Fatalf("collection %s was not created", report.Collection)
+		}
+	}
+
+	reports, err = mongostarter.ProvisionCollections(t.Context(), config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, report := range reports {
+		if report.Missing || len(report.Mismatches) > 0 {
+			t.Fatalf("expected collection in sync: %+v", report)
+		}
+	}
+
+	_ = mongostarter.RawCollection(cappedCollection).Drop(t.Context())
+	if err = mongostarter.RawDatabase().CreateCollection(t.Context(), mongostarter.ApplyNaming(cappedCollection)); err != nil {
+		t.Fatal(err)
+	}
+	reports, err = mongostarter.ProvisionCollections(t.Context(), config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(reports[0].Mismatches) == 0 {
+		t.Fatalf("expected mismatches for plain collection: %+v", reports[0])
+	}
+	t.Log(reports[0].Mismatches)
+
+	_, err = mongostarter.ProvisionCollections(t.Context(), mongostarter.ProvisionConfig{Models: []mongostarter.Model{InvalidCapped{}}})
+	if !errors.Is(err, mongostarter.ErrInvalidCollectionOptions) {
+		t.Fatalf("expected ErrInvalidCollectionOptions, got %v", err)
+	}
+}
@@ -0,0 +1,93 @@
+package test
+
+import (
+	"testing"
+
+	"github.com/golang-acexy/starter-mongo/mongostarter"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+const schemaCollection = "starter_mongo_schema_test"
+
+type ValidatedOrder struct {
+	ID       string          `bson:"_id,omitempty"`
+	Number   string          `bson:"number"`
+	Status   string          `bson:"status" schema:"enum=open|paid|closed"`
+	Amount   float64         `bson:"amount"`
+	Note     *string         `bson:"note"`
+	Tags     []string        `bson:"tags,omitempty"`
+	Customer ValidatedPerson `bson:"customer"`
+}
+
+type ValidatedPerson struct {
+	Name  string `bson:"name"`
+	Email string `bson:"email,omitempty"`
+}
+
+func (ValidatedOrder) CollectionName() string {
+	return schemaCollection
+}
+
+func TestJSONSchema(t *testing.T) {
+	schema, err := mongostarter.JSONSchema(ValidatedOrder{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	properties := schema["properties"].(bson.M)
+	if _, ok := properties["_id"]; ok {
+		t.Fatal("_id must not be constrained")
+	}
+	customer := properties["customer"].(bson.M)
+	if customer["bsonType"] != "object" || customer["required"] == nil {
+		t.Fatalf("unexpected nested schema: %v", customer)
+	}
+	required := schema["required"].([]string)
+	for _, name := range []string{"amount", "customer", "number", "status"} {
+		found := false
+		for _, item := range required {
+			found = found || item == name
+		}
+		if !found {
+			t.Fatalf("%s must be required: %v", name, required)
+		}
+	}
+}
+
+func TestSyncValidators(t *testing.T) {
+	collection := mongostarter.RawCollection(schemaCollection)
+	t.Cleanup(func() {
+		_ = collection.Drop(t.Context())
+	})
+	config := mongostarter.ValidatorConfig{Models: []mongostarter.Model{ValidatedOrder{}}}
+	reports, err := mongostarter.SyncValidators(t.Context(), config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reports[0].Created {
+		t.Fatalf("expected collection to be created: %+v", reports[0])
+	}
+	if _, err = collection.InsertOne(t.Context(), bson.M{"number": "A1", "status": "unknown", "amount": 1.5, "customer": bson.M{"name": "a"}}); err == nil {
+		t.Fatal("expected validation failure")
+	}
+	if _, err = collection.InsertOne(t.Context(), ValidatedOrder{Number: "A1", Status: "open", Amount: 1.5, Customer: ValidatedPerson{Name: "a"}}); err != nil {
+		t.Fatal(err)
+	}
+
+	reports, err = mongostarter.SyncValidators(t.Context(), config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(reports[0].Differences) > 0 || reports[0].Updated {
+		t.Fatalf("expected validator in sync: %+v", reports[0])
+	}
+
+	config.Action = "warn"
+	config.DryRun = true
+	reports, err = mongostarter.SyncValidators(t.Context(), config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(reports[0].Differences) != 1 || reports[0].Updated {
+		t.Fatalf("expected one reported difference: %+v", reports[0])
+	}
+}
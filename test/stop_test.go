@@ -0,0 +1,66 @@
+package test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang-acexy/starter-mongo/mongostarter"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+func TestStopTimeoutWithBlockedOperation(t *testing.T) {
+	resetCollection(t)
+	insertLog(t, "blocked", 1)
+	t.Cleanup(func() {
+		// 恢复全局启动器 供后续测试使用
+		if mongostarter.RawMongoClient() != nil {
+			_, _, _ = starter.Stop(time.Minute)
+		}
+		if _, err := starter.Start(); err != nil {
+			t.Error(err)
+		}
+	})
+
+	blocked := make(chan error, 1)
+	go func() {
+		var logs []*StartupLog
+		blocked <- mapper.SelectByBSON(bson.M{"$where": "sleep(1500) || true"}, nil, &logs)
+	}()
+	time.Sleep(300 * time.Millisecond)
+
+	gracefully, stopped, err := starter.Stop(200 * time.Millisecond)
+	if !errors.Is(err, mongostarter.ErrMongoStopTimeout) || gracefully || stopped {
+		t.Fatalf("expected ErrMongoStopTimeout with the operation still running, got %v %v %v", gracefully, stopped, err)
+	}
+	var timeoutErr *mongostarter.StopTimeoutError
+	if !errors.As(err, &timeoutErr) || timeoutErr.Running != 1 {
+		t.Fatalf("expected one running operation, got %v", err)
+	}
+	if mongostarter.RawMongoClient() == nil {
+		t.Fatal("expected the client to stay connected after a stop timeout")
+	}
+	if _, err = mapper.CountByBSON(bson.M{}); !errors.Is(err, mongostarter.ErrMongoStarterStopping) {
+		t.Fatalf("expected new operations to be rejected, got %v", err)
+	}
+
+	select {
+	case err = <-blocked:
+		if err != nil {
+			t.Fatalf("expected the blocked operation to finish, got %v", err)
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatal("blocked operation did not finish")
+	}
+	for len(connectionClosed) > 0 {
+		<-connectionClosed
+	}
+	if gracefully, stopped, err = starter.Stop(5 * time.Second); err != nil || !gracefully || !stopped {
+		t.Fatalf("expected a second stop to finish, got %v %v %v", gracefully, stopped, err)
+	}
+	select {
+	case <-connectionClosed:
+	default:
+		t.Fatal("expected OnConnectionClosed when the client disconnects")
+	}
+}
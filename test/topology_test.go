@@ -0,0 +1,36 @@
+package test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/golang-acexy/starter-mongo/mongostarter"
+)
+
+var connected = make(chan mongostarter.TopologyStatus, 1)
+
+func TestTopology(t *testing.T) {
+	status, err := mongostarter.Topology()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !status.Available() || len(status.Members) == 0 {
+		t.Fatalf("unexpected topology: %+v", status)
+	}
+	for _, member := range status.Members {
+		if member.Address == "" || member.State == "" {
+			t.Fatalf("unexpected member: %+v", member)
+		}
+	}
+}
+
+func TestTopologyOnConnected(t *testing.T) {
+	select {
+	case status := <-connected:
+		if !status.Available() {
+			t.Fatalf("unexpected topology on connected: %+v", status)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("OnConnected was not called")
+	}
+}
@@ -0,0 +1,67 @@
+package test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/golang-acexy/starter-mongo/mongostarter"
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+var spanExporter = tracetest.NewInMemoryExporter()
+var tracerProvider = sdktrace.NewTracerProvider(sdktrace.WithSyncer(spanExporter))
+
+func spanAttribute(span tracetest.SpanStub, key attribute.Key) (attribute.Value, bool) {
+	for _, kv := range span.Attributes {
+		if kv.Key == key {
+			return kv.Value, true
+		}
+	}
+	return attribute.Value{}, false
+}
+
+func TestTracingSpans(t *testing.T) {
+	resetCollection(t)
+	insertLog(t, "tracing", 1)
+
+	ctx, parent := tracerProvider.Tracer("test").Start(context.Background(), "parent")
+	var logs []*StartupLog
+	if err := mapper.WithContext(ctx).SelectByCond(&StartupLog{Hostname: "tracing"}, nil, &logs); err != nil {
+		t.Fatal(err)
+	}
+	parent.End()
+
+	var mapperSpan, commandSpan *tracetest.SpanStub
+	spans := spanExporter.GetSpans()
+	for i := range spans {
+		switch spans[i].Name {
+		case "SelectByCond " + mongostarter.ApplyNaming(testCollection):
+			mapperSpan = &spans[i]
+		case "find " + mongostarter.ApplyNaming(testCollection):
+			if spans[i].Parent.TraceID() == parent.SpanContext().TraceID() {
+				commandSpan = &spans[i]
+			}
+		}
+	}
+	if mapperSpan == nil || commandSpan == nil {
+		t.Fatalf("expected mapper and command spans, got %d spans", len(spans))
+	}
+	if mapperSpan.Parent.SpanID() != parent.SpanContext().SpanID() {
+		t.Fatal("mapper span is not a child of the caller span")
+	}
+	if commandSpan.Parent.SpanID() != mapperSpan.SpanContext.SpanID() {
+		t.Fatal("command span is not a child of the mapper span")
+	}
+	if commandSpan.SpanKind != trace.SpanKindClient {
+		t.Fatalf("unexpected command span kind: %v", commandSpan.SpanKind)
+	}
+	if value, ok := spanAttribute(*mapperSpan, "db.mongodb.document_count"); !ok || value.AsInt64() != 1 {
+		t.Fatalf("unexpected document count attribute: %v", value)
+	}
+	if value, ok := spanAttribute(*mapperSpan, "db.collection.name"); !ok || value.AsString() != mongostarter.ApplyNaming(testCollection) {
+		t.Fatalf("unexpected collection attribute: %v", value)
+	}
+}
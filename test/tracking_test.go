@@ -0,0 +1,127 @@
+package test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/golang-acexy/starter-mongo/mongostarter"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+type Inventory struct {
+	ID      string         `bson:"_id,omitempty"`
+	Sku     string         `bson:"sku"`
+	Stock   int            `bson:"stock"`
+	Tags    []string       `bson:"tags,omitempty"`
+	Address ProfileAddress `bson:"address"`
+	Version int64          `bson:"version"`
+}
+
+func (Inventory) CollectionName() string {
+	return testCollection
+}
+
+func (Inventory) VersionField() string {
+	return "version"
+}
+
+type InventoryMapper struct {
+	mongostarter.BaseMapper[Inventory]
+}
+
+func TestSaveChanges(t *testing.T) {
+	resetCollection(t)
+	var inventories InventoryMapper
+	id, err := inventories.Insert(&Inventory{Sku: "a-1", Stock: 5, Tags: []string{"new"}, Address: ProfileAddress{City: "Berlin", Street: "Main"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	tracked, err := inventories.TrackByID(id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if modified, err := inventories.SaveChanges(tracked); err != nil || modified != 0 {
+		t.Fatalf("expected unchanged entity to skip the write, got %d %v", modified, err)
+	}
+
+	tracked.Entity.Stock = 4
+	tracked.Entity.Address.City = "Paris"
+	tracked.Entity.Tags = nil
+	changes, err := tracked.Changes()
+	if err != nil {
+		t.Fatal(err)
+	}
+	set, _ := changes["$set"].(bson.D)
+	unset, _ := changes["$unset"].(bson.D)
+	if len(set) != 2 || set[0].Key != "stock" || set[1].Key != "address.city" || len(unset) != 1 || unset[0].Key != "tags" {
+		t.Fatalf("unexpected changes: %v", changes)
+	}
+
+	stale, err := inventories.TrackByID(id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 其他字段的并发修改不会被覆盖
+	if _, err = inventories.UpdateByIDWithBSON(bson.M{"sku": "a-2"}, id); err != nil {
+		t.Fatal(err)
+	}
+	if modified, err := inventories.SaveChanges(tracked); err != nil || modified != 1 {
+		t.Fatalf("expected one modified document, got %d %v", modified, err)
+	}
+	entity := tracked.Entity
+	if entity.Sku != "a-2" || entity.Stock != 4 || entity.Address.City != "Paris" || entity.Address.Street != "Main" || entity.Tags != nil || entity.Version != 1 {
+		t.Fatalf("unexpected entity after save: %+v", entity)
+	}
+
+	stale.Entity.Stock = 10
+	if _, err = inventories.SaveChanges(stale); !errors.Is(err, mongostarter.ErrVersionConflict) {
+		t.Fatalf("expected ErrVersionConflict, got %v", err)
+	}
+	var stored Inventory
+	if err = inventories.SelectByID(id, &stored); err != nil {
+		t.Fatal(err)
+	}
+	if stored.Stock != 4 || stored.Version != 1 {
+		t.Fatalf("stale save must not be applied: %+v", stored)
+	}
+}
+
+type AuditMeta struct {
+	Version int64  `bson:"version"`
+	Owner   string `bson:"owner"`
+}
+
+type AuditedInventory struct {
+	ID   string     `bson:"_id,omitempty"`
+	Sku  string     `bson:"sku"`
+	Meta *AuditMeta `bson:"meta,omitempty"`
+}
+
+func (AuditedInventory) CollectionName() string {
+	return testCollection
+}
+
+func (AuditedInventory) VersionField() string {
+	return "meta.version"
+}
+
+type AuditedInventoryMapper struct {
+	mongostarter.BaseMapper[AuditedInventory]
+}
+
+func TestSaveChangesVersionPath(t *testing.T) {
+	resetCollection(t)
+	var inventories AuditedInventoryMapper
+	id, err := inventories.Insert(&AuditedInventory{Sku: "b-1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	tracked, err := inventories.TrackByID(id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tracked.Entity.Meta = &AuditMeta{Owner: "ops"}
+	if _, err = inventories.SaveChanges(tracked); !errors.Is(err, mongostarter.ErrVersionPath) {
+		t.Fatalf("expected ErrVersionPath when the version parent is replaced, got %v", err)
+	}
+}
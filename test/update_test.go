@@ -0,0 +1,87 @@
+package test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/golang-acexy/starter-mongo/mongostarter"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+type ProfileAddress struct {
+	City   string `bson:"city"`
+	Street string `bson:"street"`
+}
+
+type Profile struct {
+	ID       string         `bson:"_id,omitempty"`
+	Hostname string         `bson:"hostname"`
+	Visits   int            `bson:"visits"`
+	Note     *string        `bson:"note"`
+	Address  ProfileAddress `bson:"address"`
+}
+
+func (Profile) CollectionName() string {
+	return testCollection
+}
+
+type ProfileMapper struct {
+	mongostarter.BaseMapper[Profile]
+}
+
+func TestPartialUpdate(t *testing.T) {
+	resetCollection(t)
+	var profiles ProfileMapper
+	note := "first"
+	id, err := profiles.Insert(&Profile{
+		Hostname: "api",
+		Visits:   3,
+		Note:     &note,
+		Address:  ProfileAddress{City: "Berlin", Street: "Main"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	load := func() Profile {
+		t.Helper()
+		var profile Profile
+		if err := profiles.SelectByID(id, &profile); err != nil {
+			t.Fatal(err)
+		}
+		return profile
+	}
+
+	partial := profiles.WithUpdateMode(mongostarter.UpdatePartial)
+	update := &Profile{}
+	update.Address.City = "Paris"
+	if _, err = partial.UpdateByID(update, id); err != nil {
+		t.Fatal(err)
+	}
+	profile := load()
+	if profile.Address.City != "Paris" || profile.Address.Street != "Main" || profile.Visits != 3 || profile.Hostname != "api" {
+		t.Fatalf("partial update replaced unrelated fields: %+v", profile)
+	}
+
+	if _, err = partial.UpdateByID(&Profile{}, id); !errors.Is(err, mongostarter.ErrEmptyUpdate) {
+		t.Fatalf("expected ErrEmptyUpdate, got %v", err)
+	}
+
+	if _, err = profiles.WithFieldMask("visits", "note", "address.street").UpdateByID(&Profile{Hostname: "ignored"}, id); err != nil {
+		t.Fatal(err)
+	}
+	profile = load()
+	if profile.Visits != 0 || profile.Note != nil || profile.Address.Street != "" || profile.Address.City != "Paris" || profile.Hostname != "api" {
+		t.Fatalf("field mask did not write exactly the masked paths: %+v", profile)
+	}
+	raw, err := profiles.Collection().FindOne(t.Context(), bson.M{"hostname": "api"}).Raw()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if value := raw.Lookup("note"); value.Type != bson.TypeNull {
+		t.Fatalf("expected note to be null, got %s", value.Type)
+	}
+
+	if _, err = profiles.WithFieldMask("unknown").UpdateByID(&Profile{}, id); !errors.Is(err, mongostarter.ErrInvalidFieldMask) {
+		t.Fatalf("expected ErrInvalidFieldMask, got %v", err)
+	}
+}